@@ -0,0 +1,226 @@
+package initializr
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Archive formats the Initializr can serve projects in
+const (
+	ArchiveZip = "zip"
+	ArchiveTgz = "tgz"
+)
+
+// ArchiveFileName returns the file name the Initializr uses for a project archive of the given format
+func ArchiveFileName(format string) (string, error) {
+	switch format {
+	case ArchiveZip, ArchiveTgz:
+		return "starter." + format, nil
+	default:
+		return "", fmt.Errorf("archive format must be %s or %s, got %q", ArchiveZip, ArchiveTgz, format)
+	}
+}
+
+// WithArchiveFormat rewrites a project URL, as returned by Client.ProjectURL, to request the
+// given archive format. Only project types, which are served as starter.zip, can be rewritten
+func WithArchiveFormat(projectURL *url.URL, format string) (*url.URL, error) {
+	fileName, err := ArchiveFileName(format)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, file := path.Split(projectURL.Path)
+	if file != "starter.zip" && file != "starter.tgz" {
+		return nil, fmt.Errorf("%s does not generate a project archive, so archive format %s cannot be used", projectURL.Path, format)
+	}
+
+	u := *projectURL
+	u.Path = dir + fileName
+	u.RawPath = ""
+
+	return &u, nil
+}
+
+// Unpack extracts a zip or tar.gz project archive, chosen by its file extension. See UnpackZip
+func Unpack(archivePath, destDir string, stripComponents int) ([]string, error) {
+	switch {
+	case strings.HasSuffix(archivePath, ".zip"):
+		return UnpackZip(archivePath, destDir, stripComponents)
+	case strings.HasSuffix(archivePath, ".tgz"), strings.HasSuffix(archivePath, ".tar.gz"):
+		return UnpackTarGz(archivePath, destDir, stripComponents)
+	default:
+		return nil, fmt.Errorf("%s is not a zip or tar.gz archive", archivePath)
+	}
+}
+
+// wrapper scripts that must stay executable even if the archive does not record a mode
+var executableNames = map[string]bool{
+	"mvnw":    true,
+	"gradlew": true,
+}
+
+// UnpackZip extracts a zip archive such as starter.zip into destDir, dropping the first
+// stripComponents path elements of every entry. Entries that would land outside destDir
+// are rejected. It returns the paths of the extracted files, relative to destDir
+func UnpackZip(archivePath, destDir string, stripComponents int) ([]string, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var written []string
+	for _, f := range reader.File {
+		name, ok, err := entryPath(f.Name, stripComponents)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		target := filepath.Join(destDir, filepath.FromSlash(name))
+		if f.FileInfo().IsDir() {
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		src, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeEntry(target, src, entryMode(name, f.Mode()))
+		src.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		written = append(written, name)
+	}
+
+	return written, nil
+}
+
+// UnpackTarGz extracts a gzipped tar archive such as starter.tgz the same way UnpackZip
+// extracts a zip archive. Links are rejected, since the Initializr never generates them
+func UnpackTarGz(archivePath, destDir string, stripComponents int) ([]string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	var written []string
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok, err := entryPath(header.Name, stripComponents)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		target := filepath.Join(destDir, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return nil, err
+			}
+		case tar.TypeReg:
+			if err = writeEntry(target, reader, entryMode(name, header.FileInfo().Mode())); err != nil {
+				return nil, err
+			}
+
+			written = append(written, name)
+		case tar.TypeSymlink, tar.TypeLink:
+			return nil, fmt.Errorf("archive entry %q is a link, which is not supported", header.Name)
+		}
+	}
+
+	return written, nil
+}
+
+// entryPath cleans an archive entry name and strips leading components from it. Names such as
+// ./demo/pom.xml are cleaned first, and only absolute names or ones that climb out of the
+// destination with .. are rejected. ok is false if nothing is left after stripping
+func entryPath(name string, stripComponents int) (string, bool, error) {
+	slashed := strings.Replace(name, "\\", "/", -1)
+	cleaned := path.Clean(slashed)
+
+	// a drive letter such as C: makes a Windows path absolute
+	if path.IsAbs(slashed) || (len(cleaned) >= 2 && cleaned[1] == ':') || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false, fmt.Errorf("archive entry %q points outside of the destination directory", name)
+	}
+
+	parts := strings.Split(cleaned, "/")
+	if cleaned == "." || len(parts) <= stripComponents {
+		return "", false, nil
+	}
+
+	return strings.Join(parts[stripComponents:], "/"), true, nil
+}
+
+func entryMode(name string, mode os.FileMode) os.FileMode {
+	perm := mode.Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+
+	if executableNames[path.Base(name)] {
+		perm |= 0111
+	}
+
+	return perm
+}
+
+func writeEntry(target string, src io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	// OpenFile's mode is subject to the umask and ignored for existing files
+	return os.Chmod(target, mode)
+}
@@ -0,0 +1,191 @@
+package initializr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// ReleaseType classifies a Spring Boot version by its qualifier. Values are ordered
+// the same way the Initializr orders qualifiers for the same x.y.z version
+type ReleaseType int
+
+const (
+	// Milestone is an x.y.z.Mn or x.y.z-Mn build
+	Milestone ReleaseType = iota
+	// ReleaseCandidate is an x.y.z.RCn or x.y.z-RCn build
+	ReleaseCandidate
+	// Snapshot is an x.y.z.BUILD-SNAPSHOT or x.y.z-SNAPSHOT build
+	Snapshot
+	// GA is an x.y.z.RELEASE or unqualified x.y.z build
+	GA
+)
+
+var releaseTypeNames = map[ReleaseType]string{
+	Milestone:        "milestone",
+	ReleaseCandidate: "rc",
+	Snapshot:         "snapshot",
+	GA:               "ga",
+}
+
+func (r ReleaseType) String() string {
+	if name, ok := releaseTypeNames[r]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ReleaseType(%d)", int(r))
+}
+
+// ParseReleaseType converts a release channel name (ga, rc, milestone or snapshot) to a ReleaseType
+func ParseReleaseType(name string) (ReleaseType, error) {
+	for r, n := range releaseTypeNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			return r, nil
+		}
+	}
+
+	return GA, fmt.Errorf("unknown release channel %q, expected one of ga, rc, milestone or snapshot", name)
+}
+
+var bootVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:[.-]([A-Za-z][A-Za-z-]*?)(\d*))?$`)
+
+// BootVersion is a Spring Boot version ID as listed by the Initializr, parsed from either
+// the legacy x.y.z.QUALIFIER scheme or the x.y.z[-QUALIFIER] scheme used since 2.4
+type BootVersion struct {
+	ID          string
+	Major       uint64
+	Minor       uint64
+	Patch       uint64
+	ReleaseType ReleaseType
+	// Increment is the milestone or release candidate number, e.g. 2 for 3.1.0-M2
+	Increment uint64
+}
+
+// ParseBootVersion parses a bootVersion ID such as 2.1.0.RELEASE, 2.3.0.M1, 3.2.0-RC1 or 3.3.0-SNAPSHOT
+func ParseBootVersion(id string) (BootVersion, error) {
+	matches := bootVersionPattern.FindStringSubmatch(strings.TrimSpace(id))
+	if matches == nil {
+		return BootVersion{}, fmt.Errorf("%q is not a recognized Spring Boot version", id)
+	}
+
+	v := BootVersion{ID: id}
+	var err error
+	for i, part := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if *part, err = strconv.ParseUint(matches[i+1], 10, 64); err != nil {
+			return BootVersion{}, fmt.Errorf("%q is not a recognized Spring Boot version: %s", id, err.Error())
+		}
+	}
+
+	qualifier, increment := strings.ToUpper(matches[4]), matches[5]
+	switch qualifier {
+	case "", "RELEASE":
+		v.ReleaseType = GA
+	case "BUILD-SNAPSHOT", "SNAPSHOT":
+		v.ReleaseType = Snapshot
+	case "M":
+		v.ReleaseType = Milestone
+	case "RC":
+		v.ReleaseType = ReleaseCandidate
+	default:
+		return BootVersion{}, fmt.Errorf("%q has an unknown qualifier %s", id, matches[4])
+	}
+
+	if increment != "" {
+		if v.ReleaseType != Milestone && v.ReleaseType != ReleaseCandidate {
+			return BootVersion{}, fmt.Errorf("%q has an unknown qualifier %s%s", id, matches[4], increment)
+		}
+
+		if v.Increment, err = strconv.ParseUint(increment, 10, 64); err != nil {
+			return BootVersion{}, fmt.Errorf("%q is not a recognized Spring Boot version: %s", id, err.Error())
+		}
+	} else if v.ReleaseType == Milestone || v.ReleaseType == ReleaseCandidate {
+		return BootVersion{}, fmt.Errorf("%q is missing its %s number", id, v.ReleaseType)
+	}
+
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 if v is older than, the same as, or newer than o
+func (v BootVersion) Compare(o BootVersion) int {
+	pairs := [][2]uint64{
+		{v.Major, o.Major},
+		{v.Minor, o.Minor},
+		{v.Patch, o.Patch},
+		{uint64(v.ReleaseType), uint64(o.ReleaseType)},
+		{v.Increment, o.Increment},
+	}
+
+	for _, p := range pairs {
+		if p[0] < p[1] {
+			return -1
+		}
+
+		if p[0] > p[1] {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// LessThan is true if v is older than o
+func (v BootVersion) LessThan(o BootVersion) bool {
+	return v.Compare(o) < 0
+}
+
+// Semver converts v to a semantic version. Qualifiers become pre-release identifiers
+// (M.2, RC.1, SNAPSHOT) so that semver precedence matches the Initializr's ordering
+func (v BootVersion) Semver() semver.Version {
+	sv := semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+
+	switch v.ReleaseType {
+	case Milestone:
+		sv.Pre = []semver.PRVersion{{VersionStr: "M"}, {VersionNum: v.Increment, IsNum: true}}
+	case ReleaseCandidate:
+		sv.Pre = []semver.PRVersion{{VersionStr: "RC"}, {VersionNum: v.Increment, IsNum: true}}
+	case Snapshot:
+		sv.Pre = []semver.PRVersion{{VersionStr: "SNAPSHOT"}}
+	}
+
+	return sv
+}
+
+func (v BootVersion) String() string {
+	return v.ID
+}
+
+// BootVersions implements sort.Interface, oldest first
+type BootVersions []BootVersion
+
+func (b BootVersions) Len() int {
+	return len(b)
+}
+
+func (b BootVersions) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
+
+func (b BootVersions) Less(i, j int) bool {
+	return b[i].LessThan(b[j])
+}
+
+// Previous returns the newest version in the list that is older than v and has the same
+// release type, which is what a new version is usually compared against
+func (b BootVersions) Previous(v BootVersion) (BootVersion, bool) {
+	var previous BootVersion
+	found := false
+	for _, candidate := range b {
+		if candidate.ReleaseType != v.ReleaseType || !candidate.LessThan(v) {
+			continue
+		}
+
+		if !found || previous.LessThan(candidate) {
+			previous, found = candidate, true
+		}
+	}
+
+	return previous, found
+}
@@ -0,0 +1,89 @@
+package initializr
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// BuildFiles are the names of the build files the Initializr generates, in the order they
+// are looked for
+var BuildFiles = []string{"pom.xml", "build.gradle", "build.gradle.kts"}
+
+// IsBuildFile reports whether name is one of BuildFiles
+func IsBuildFile(name string) bool {
+	for _, buildFile := range BuildFiles {
+		if name == buildFile {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NoBuildFileError is returned for a directory or archive that has none of BuildFiles
+type NoBuildFileError struct {
+	Path string
+}
+
+func (e NoBuildFileError) Error() string {
+	return fmt.Sprintf("%s does not contain any of %s", e.Path, strings.Join(BuildFiles, ", "))
+}
+
+// FindBuildFile returns the name of the first of BuildFiles in a project directory
+func FindBuildFile(dir string) (string, error) {
+	for _, name := range BuildFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return name, nil
+		}
+	}
+
+	return "", NoBuildFileError{Path: dir}
+}
+
+// ReadBuildFile reads a generated build file, or the top-most one inside a generated
+// starter.zip or starter.tgz. It returns the build file's name, e.g. build.gradle.kts
+func ReadBuildFile(p string) (string, []byte, error) {
+	if !strings.HasSuffix(p, ".zip") && !strings.HasSuffix(p, ".tgz") && !strings.HasSuffix(p, ".tar.gz") {
+		name := path.Base(p)
+		if !IsBuildFile(name) {
+			return "", nil, fmt.Errorf("%s is not one of %s", p, strings.Join(BuildFiles, ", "))
+		}
+
+		contents, err := ioutil.ReadFile(p)
+		return name, contents, err
+	}
+
+	var entries []archiveEntry
+	var err error
+	if strings.HasSuffix(p, ".zip") {
+		entries, err = readZipEntries(p)
+	} else {
+		entries, err = readTarGzEntries(p)
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	var found *archiveEntry
+	for i, entry := range entries {
+		if entry.dir || !IsBuildFile(path.Base(entry.name)) {
+			continue
+		}
+
+		depth := strings.Count(entry.name, "/")
+		if found == nil || depth < strings.Count(found.name, "/") {
+			found = &entries[i]
+		}
+	}
+
+	if found == nil {
+		return "", nil, NoBuildFileError{Path: p}
+	}
+
+	return path.Base(found.name), found.body, nil
+}
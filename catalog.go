@@ -0,0 +1,111 @@
+package initializr
+
+import (
+	"sort"
+)
+
+// CatalogEntry is everything known about one dependency for a Boot version: how the
+// Initializr presents it in the root metadata and the coordinates it resolves to in the
+// /dependencies document
+type CatalogEntry struct {
+	ID         string `json:"id" yaml:"id"`
+	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
+	Group      string `json:"group,omitempty" yaml:"group,omitempty"`
+	GroupID    string `json:"groupId" yaml:"groupId"`
+	ArtifactID string `json:"artifactId" yaml:"artifactId"`
+	Version    string `json:"version,omitempty" yaml:"version,omitempty"`
+	Scope      string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	BOM        string `json:"bom,omitempty" yaml:"bom,omitempty"`
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+}
+
+// BuildCatalog joins the root metadata with the /dependencies document for a Boot version.
+// Entries are sorted by group, then ID, so the output does not depend on map ordering
+func BuildCatalog(metadata *Metadata, catalog *DependencyCatalog) []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(catalog.Dependencies))
+	for id, coordinates := range catalog.Dependencies {
+		entry := CatalogEntry{
+			ID:         id,
+			GroupID:    coordinates.GroupID,
+			ArtifactID: coordinates.ArtifactID,
+			Version:    coordinates.Version,
+			Scope:      coordinates.Scope,
+			BOM:        coordinates.BOM,
+			Repository: coordinates.Repository,
+		}
+
+		if dep, group, ok := metadata.Dependency(id); ok {
+			entry.Name = dep.Name
+			entry.Group = group
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Group != entries[j].Group {
+			return entries[i].Group < entries[j].Group
+		}
+
+		return entries[i].ID < entries[j].ID
+	})
+
+	return entries
+}
+
+// BOMEntry is a BOM from the /dependencies document along with its ID
+type BOMEntry struct {
+	ID           string   `json:"id" yaml:"id"`
+	GroupID      string   `json:"groupId" yaml:"groupId"`
+	ArtifactID   string   `json:"artifactId" yaml:"artifactId"`
+	Version      string   `json:"version" yaml:"version"`
+	Repositories []string `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+}
+
+// RepositoryEntry is a repository from the /dependencies document along with its ID
+type RepositoryEntry struct {
+	ID              string `json:"id" yaml:"id"`
+	Name            string `json:"name" yaml:"name"`
+	URL             string `json:"url" yaml:"url"`
+	SnapshotEnabled bool   `json:"snapshotEnabled" yaml:"snapshotEnabled"`
+}
+
+// BOMList returns the catalog's BOMs sorted by ID. It is never nil, so it encodes as a JSON list
+func (c *DependencyCatalog) BOMList() []BOMEntry {
+	boms := make([]BOMEntry, 0, len(c.BOMs))
+	for id, bom := range c.BOMs {
+		boms = append(boms, BOMEntry{
+			ID:           id,
+			GroupID:      bom.GroupID,
+			ArtifactID:   bom.ArtifactID,
+			Version:      bom.Version,
+			Repositories: bom.Repositories,
+		})
+	}
+
+	sort.Slice(boms, func(i, j int) bool {
+		return boms[i].ID < boms[j].ID
+	})
+
+	return boms
+}
+
+// RepositoryList returns the catalog's repositories sorted by ID. It is never nil, so it
+// encodes as a JSON list
+func (c *DependencyCatalog) RepositoryList() []RepositoryEntry {
+	repos := make([]RepositoryEntry, 0, len(c.Repositories))
+	for id, repo := range c.Repositories {
+		repos = append(repos, RepositoryEntry{
+			ID:              id,
+			Name:            repo.Name,
+			URL:             repo.URL,
+			SnapshotEnabled: repo.SnapshotEnabled,
+		})
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].ID < repos[j].ID
+	})
+
+	return repos
+}
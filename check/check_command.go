@@ -1,14 +1,11 @@
 package check
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"log"
 	"net/http"
-	"sort"
 	"strings"
 
-	"github.com/blang/semver"
 	"github.com/jghiloni/spring-initializr-resource"
 )
 
@@ -17,100 +14,102 @@ type Command struct {
 	Client *http.Client
 }
 
-// Run will check the specified initializr site and report back new versions from the last check
+// Run will check the specified initializr site and report back new versions from the last check.
+// Versions are reported oldest first and include the current version while it is still listed
 func (command *Command) Run(request Request) (Response, error) {
-	httpResponseBody := struct {
-		BootVersion bootVersion `json:"bootVersion"`
-	}{}
-
-	req, err := http.NewRequest("GET", request.Source.URL.String(), nil)
+	client := &initializr.Client{HTTPClient: command.Client, URL: request.Source.URL}
+	metadata, err := client.Metadata()
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Accept", initializr.AcceptHeader)
+	if request.Source.Track == initializr.TrackDefault {
+		return defaultVersion(metadata.BootVersion)
+	}
 
-	httpResponse, err := command.Client.Do(req)
+	available, err := availableVersions(client, metadata, request.Source.RequiredDependencies)
 	if err != nil {
 		return nil, err
 	}
 
-	respBody, err := ioutil.ReadAll(httpResponse.Body)
+	if request.Source.Track == initializr.TrackDependencies {
+		return catalogVersion(client, available, request.Source)
+	}
+
+	versions, err := available.CheckVersions(request.Source, request.Version, metadata.BootVersion)
 	if err != nil {
 		return nil, err
 	}
 
-	if httpResponse.StatusCode != 200 {
-		return nil, fmt.Errorf("Expected 200 OK, got %d %s with message %s", httpResponse.StatusCode, httpResponse.Status, string(respBody))
-	}
+	return Response(versions), nil
+}
 
-	if err = json.Unmarshal(respBody, &httpResponseBody); err != nil {
-		return nil, err
+// availableVersions returns a copy of the metadata that lists only the Boot versions every
+// required dependency is available for
+func availableVersions(client *initializr.Client, metadata *initializr.Metadata, required []string) (*initializr.Metadata, error) {
+	if len(required) == 0 {
+		return metadata, nil
 	}
 
-	versions := make([]comparableVersion, 0, len(httpResponseBody.BootVersion.Values))
-	for _, value := range httpResponseBody.BootVersion.Values {
-		buildVersion, releaseType := parseVersion(value)
+	available := *metadata
+	available.BootVersion.Values = nil
+	for _, value := range metadata.BootVersion.Values {
+		buildVersion, err := initializr.ParseBootVersion(value.ID)
+		if err != nil {
+			return nil, err
+		}
 
-		if !request.Source.IncludeSnapshots && !strings.EqualFold(releaseType, "RELEASE") {
-			continue
+		unavailable, err := client.UnavailableDependencies(required, buildVersion)
+		if err != nil {
+			return nil, err
 		}
 
-		if request.Source.ProductVersion != nil && !request.Source.ProductVersion.Match([]byte(value.ID)) {
+		if len(unavailable) > 0 {
+			log.Printf("skipping Spring Boot %s, required dependencies %s are not available for it", value.ID, strings.Join(unavailable, ", "))
 			continue
 		}
 
-		//value.BuildNum = semver.MustParse(verNum)
-		versions = append(versions, comparableVersion{
-			version:      value,
-			buildVersion: buildVersion,
-		})
+		available.BootVersion.Values = append(available.BootVersion.Values, value)
 	}
 
-	sort.Slice(versions, func(i, j int) bool {
-		return versions[i].buildVersion.Compare(versions[j].buildVersion) > 0
-	})
+	return &available, nil
+}
 
-	if request.Version != nil {
-		ver, _ := parseVersion(*request.Version)
-		compRequestVersion := comparableVersion{
-			version:      *request.Version,
-			buildVersion: ver,
-		}
+// catalogVersion returns the digest of the dependency catalog for the newest version the
+// source includes. It is the current version for as long as the catalog does not change
+func catalogVersion(client *initializr.Client, metadata *initializr.Metadata, source initializr.Source) (Response, error) {
+	listed, err := metadata.BootVersions()
+	if err != nil {
+		return nil, err
+	}
 
-		for i := range versions {
-			if versions[i].buildVersion.Compare(compRequestVersion.buildVersion) <= 0 {
-				return unwrapVersions(versions[:i]), nil
-			}
+	var newest *initializr.BootVersion
+	for i, v := range listed {
+		if source.IncludesVersion(v) && (newest == nil || newest.LessThan(v)) {
+			newest = &listed[i]
 		}
 	}
 
-	return unwrapVersions(versions), nil
-}
-
-func parseVersion(value initializr.Version) (semver.Version, string) {
-	parts := strings.SplitN(value.ID, ".", 4)
-	verNum := strings.Join(parts[0:3], ".")
-	releaseType := parts[3]
-
-	return semver.MustParse(verNum), releaseType
-}
+	if newest == nil {
+		return Response{}, nil
+	}
 
-func unwrapVersions(versions []comparableVersion) Response {
-	resp := make(Response, 0, len(versions))
-	for _, cv := range versions {
-		resp = append(resp, cv.version)
+	catalogVersion, err := client.DependencyCatalogVersion(*newest)
+	if err != nil {
+		return nil, err
 	}
 
-	return resp
+	return Response{catalogVersion}, nil
 }
 
-type bootVersion struct {
-	Default string               `json:"default"`
-	Values  []initializr.Version `json:"values"`
-}
+// defaultVersion returns the Initializr's default Boot version. It is the current version
+// for as long as the default does not change
+func defaultVersion(bv initializr.SelectField) (Response, error) {
+	for _, value := range bv.Values {
+		if value.ID == bv.Default {
+			return Response{{ID: value.ID, Name: value.Name}}, nil
+		}
+	}
 
-type comparableVersion struct {
-	version      initializr.Version
-	buildVersion semver.Version
+	return nil, fmt.Errorf("the Initializr's default Spring Boot version %q is not one of the versions it lists", bv.Default)
 }
@@ -1,11 +1,14 @@
 package check_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -23,17 +26,54 @@ import (
 func TestCheckCommand(t *testing.T) {
 	spec.Run(t, "Check Command", func(t *testing.T, when spec.G, it spec.S) {
 		when("Testing the check command", func() {
-			var request check.Request
-
 			var initializrServer *httptest.Server
+			var fakeClient *http.Client
 
-			it.Before(func() {
-				RegisterTestingT(t)
-
-				dataDir, err := filepath.Abs("testdata")
+			// startServer serves the initializr.json in the given testdata directory
+			startServer := func(dir string) {
+				dataDir, err := filepath.Abs(dir)
 				Expect(err).NotTo(HaveOccurred())
 
 				initializrServer = internal.MockInitializrServer(dataDir)
+
+				fakeClient, err = initializr.NewHTTPClient(initializr.Source{SkipTLSValidation: true})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			// runRequest runs check with the request in the given file against the mock server
+			runRequest := func(requestFile string) (check.Response, error) {
+				bytes, err := ioutil.ReadFile(requestFile)
+				Expect(err).NotTo(HaveOccurred())
+
+				var request check.Request
+				err = json.Unmarshal(bytes, &request)
+				Expect(err).NotTo(HaveOccurred())
+
+				request.Source.URL, err = url.Parse(initializrServer.URL)
+				Expect(err).NotTo(HaveOccurred())
+
+				cmd := &check.Command{
+					Client: fakeClient,
+				}
+
+				return cmd.Run(request)
+			}
+
+			// runVersion runs check against the mock server with the given source and current version
+			runVersion := func(source initializr.Source, current *initializr.Version) (check.Response, error) {
+				serverURL, err := url.Parse(initializrServer.URL)
+				Expect(err).NotTo(HaveOccurred())
+
+				source.URL = serverURL
+				cmd := &check.Command{
+					Client: fakeClient,
+				}
+
+				return cmd.Run(check.Request{Source: source, Version: current})
+			}
+
+			it.Before(func() {
+				RegisterTestingT(t)
 			})
 
 			it.After(func() {
@@ -41,128 +81,329 @@ func TestCheckCommand(t *testing.T) {
 			})
 
 			when("I test basic check functionality", func() {
-				var fakeClient *http.Client
-
 				it.Before(func() {
-					var err error
-					serverURL, err := url.Parse(initializrServer.URL)
-					Expect(err).NotTo(HaveOccurred())
-
-					baseSource := initializr.Source{
-						URL:               serverURL,
-						SkipTLSValidation: true,
-					}
-
-					fakeClient, err = initializr.NewHTTPClient(baseSource)
-					Expect(err).NotTo(HaveOccurred())
+					startServer("testdata")
 				})
 
 				when("I get versions for the first time", func() {
-					it("returns all the release versions", func() {
-						bytes, err := ioutil.ReadFile("testdata/first_request.json")
+					it("returns only the latest release version", func() {
+						resp, err := runRequest("testdata/first_request.json")
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "2.0.2.RELEASE", Name: "2.0.2"}}))
+					})
 
-						err = json.Unmarshal(bytes, &request)
+					it("returns only the latest version", func() {
+						resp, err := runRequest("testdata/first_request_with_snapshots.json")
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "2.1.0.BUILD-SNAPSHOT", Name: "2.1.0 (SNAPSHOT)"}}))
+					})
+				})
 
-						cmd := &check.Command{
-							Client: fakeClient,
-						}
+				when("I have checked recently", func() {
+					it("returns the versions newer than a delisted current version", func() {
+						resp, err := runRequest("testdata/subsequent_request.json")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "2.0.2.RELEASE", Name: "2.0.2"}}))
+					})
 
-						resp, err := cmd.Run(request)
+					it("returns all later versions, oldest first", func() {
+						resp, err := runRequest("testdata/subsequent_request_with_snapshots.json")
 						Expect(err).NotTo(HaveOccurred())
-						Expect(resp).To(HaveLen(2))
-						Expect(resp[0].ID).To(Equal("2.0.2.RELEASE"))
+						Expect(resp).To(Equal(check.Response{
+							{ID: "2.0.2.RELEASE", Name: "2.0.2"},
+							{ID: "2.0.3.BUILD-SNAPSHOT", Name: "2.0.3 (SNAPSHOT)"},
+							{ID: "2.1.0.BUILD-SNAPSHOT", Name: "2.1.0 (SNAPSHOT)"},
+						}))
 					})
+				})
 
-					it("returns all the versions", func() {
-						bytes, err := ioutil.ReadFile("testdata/first_request_with_snapshots.json")
+				when("I require dependencies with legacy version ranges", func() {
+					it("honors a bare minimum version", func() {
+						resp, err := runRequest("testdata/first_request_requiring_webflux.json")
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "2.0.2.RELEASE", Name: "2.0.2"}}))
+					})
 
-						err = json.Unmarshal(bytes, &request)
+					it("honors a bounded range", func() {
+						resp, err := runRequest("testdata/first_request_requiring_mobile.json")
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "1.5.13.RELEASE", Name: "1.5.13"}}))
+					})
+				})
 
-						cmd := &check.Command{
-							Client: fakeClient,
-						}
+				when("I have pinned to a specific major minor version", func() {
+					it("returns only the current version", func() {
+						resp, err := runRequest("testdata/subsequent_request_with_pin.json")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "1.5.13.RELEASE", Name: "1.5.13"}}))
+					})
 
-						resp, err := cmd.Run(request)
+					it("returns the current version and a snapshot with snapshots enabled", func() {
+						resp, err := runRequest("testdata/subsequent_request_with_pin_and_snapshots.json")
 						Expect(err).NotTo(HaveOccurred())
-						Expect(resp).To(HaveLen(5))
-						Expect(resp[0].ID).To(Equal("2.1.0.BUILD-SNAPSHOT"))
+						Expect(resp).To(Equal(check.Response{
+							{ID: "1.5.13.RELEASE", Name: "1.5.13"},
+							{ID: "1.5.14.BUILD-SNAPSHOT", Name: "1.5.14 (SNAPSHOT)"},
+						}))
 					})
 				})
+			})
 
-				when("I have checked recently", func() {
-					it("returns only the latest version", func() {
-						bytes, err := ioutil.ReadFile("testdata/subsequent_request.json")
+			when("the Initializr lists versions in the post-2.4 scheme", func() {
+				it.Before(func() {
+					startServer("testdata/modern")
+				})
+
+				it("returns only the latest GA version by default", func() {
+					resp, err := runRequest("testdata/first_request.json")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{{ID: "3.1.5", Name: "3.1.5"}}))
+				})
+
+				it("adds snapshots but not milestones or release candidates with include_snapshots", func() {
+					resp, err := runRequest("testdata/first_request_with_snapshots.json")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{{ID: "3.2.0-SNAPSHOT", Name: "3.2.0 (SNAPSHOT)"}}))
+				})
+
+				it("returns only the release channels that were asked for", func() {
+					resp, err := runRequest("testdata/first_request_with_release_channels.json")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{{ID: "3.2.0-RC1", Name: "3.2.0 (RC1)"}}))
+				})
+
+				it("returns only the versions that satisfy version_constraint", func() {
+					resp, err := runRequest("testdata/first_request_with_constraint.json")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{{ID: "3.1.5", Name: "3.1.5"}}))
+				})
+
+				it("rejects a version_constraint that is not a semver range", func() {
+					var request check.Request
+					err := json.Unmarshal([]byte(`{"source": {"version_constraint": ">=3.x.y"}}`), &request)
+					Expect(err).To(MatchError(ContainSubstring(`version_constraint ">=3.x.y" is not a valid semver range`)))
+				})
+
+				it("skips versions that a required dependency is not available for", func() {
+					resp, err := runRequest("testdata/modern/first_request_with_required_dependencies.json")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{{ID: "2.7.18", Name: "2.7.18"}}))
+				})
+
+				it("lists the valid IDs when a required dependency is not known", func() {
+					_, err := runRequest("testdata/modern/first_request_with_unknown_required_dependencies.json")
+					Expect(err).To(MatchError("required dependencies graphq are not known to the Initializr; valid IDs are web, graphql, data-jpa"))
+				})
+
+				when("I track the default version", func() {
+					it("returns the default version", func() {
+						resp, err := runRequest("testdata/modern/first_request_track_default.json")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "3.1.5", Name: "3.1.5"}}))
+					})
+
+					it("returns the default version when it has changed", func() {
+						resp, err := runRequest("testdata/modern/subsequent_request_track_default.json")
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "3.1.5", Name: "3.1.5"}}))
+					})
 
-						err = json.Unmarshal(bytes, &request)
+					it("returns the current version when the default has not changed", func() {
+						resp, err := runRequest("testdata/modern/unchanged_request_track_default.json")
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "3.1.5", Name: "3.1.5"}}))
+					})
+				})
 
-						cmd := &check.Command{
-							Client: fakeClient,
-						}
+				when("I track the dependency catalog", func() {
+					var catalogDigest string
 
-						resp, err := cmd.Run(request)
+					it.Before(func() {
+						catalog, err := ioutil.ReadFile("testdata/modern/dependencies")
+						Expect(err).NotTo(HaveOccurred())
+
+						catalogDigest, err = initializr.CatalogDigest(catalog)
 						Expect(err).NotTo(HaveOccurred())
-						Expect(resp).To(HaveLen(1))
-						Expect(resp[0].ID).To(Equal("2.0.2.RELEASE"))
 					})
 
-					it("returns all later versions", func() {
-						bytes, err := ioutil.ReadFile("testdata/subsequent_request_with_snapshots.json")
+					it("returns a digest of the catalog for the newest version", func() {
+						resp, err := runRequest("testdata/modern/first_request_track_dependencies.json")
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: catalogDigest, Name: "3.1.5"}}))
+					})
 
-						err = json.Unmarshal(bytes, &request)
+					it("returns the current version when the catalog has not changed", func() {
+						bytes, err := ioutil.ReadFile("testdata/modern/first_request_track_dependencies.json")
 						Expect(err).NotTo(HaveOccurred())
 
-						cmd := &check.Command{
-							Client: fakeClient,
-						}
+						var request check.Request
+						Expect(json.Unmarshal(bytes, &request)).To(Succeed())
 
-						resp, err := cmd.Run(request)
+						request.Source.URL, err = url.Parse(initializrServer.URL)
 						Expect(err).NotTo(HaveOccurred())
-						Expect(resp).To(HaveLen(3))
-						Expect(resp[0].ID).To(Equal("2.1.0.BUILD-SNAPSHOT"))
+						request.Version = &initializr.Version{ID: catalogDigest, Name: "3.1.5"}
+
+						resp, err := (&check.Command{Client: fakeClient}).Run(request)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{*request.Version}))
 					})
 				})
 
-				when("I have pinned to a specific major minor version", func() {
-					it("returns no versions", func() {
-						bytes, err := ioutil.ReadFile("testdata/subsequent_request_with_pin.json")
-						Expect(err).NotTo(HaveOccurred())
+				it("returns the current version and the versions newer than it, oldest first", func() {
+					resp, err := runRequest("testdata/modern/subsequent_request.json")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{
+						{ID: "3.0.12", Name: "3.0.12"},
+						{ID: "3.1.5", Name: "3.1.5"},
+					}))
+				})
+			})
+
+			when("I follow the Concourse check contract", func() {
+				var source initializr.Source
+
+				it.Before(func() {
+					startServer("testdata/modern")
+					Expect(json.Unmarshal([]byte(`{}`), &source)).To(Succeed())
+				})
+
+				it("returns only the latest version when there is no current version", func() {
+					resp, err := runVersion(source, nil)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{{ID: "3.1.5", Name: "3.1.5"}}))
+				})
 
-						err = json.Unmarshal(bytes, &request)
+				it("returns only the current version when it is the latest", func() {
+					resp, err := runVersion(source, &initializr.Version{ID: "3.1.5"})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{{ID: "3.1.5", Name: "3.1.5"}}))
+				})
+
+				it("returns the current version first and newer versions in ascending order", func() {
+					resp, err := runVersion(source, &initializr.Version{ID: "2.7.18"})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{
+						{ID: "2.7.18", Name: "2.7.18"},
+						{ID: "3.0.12", Name: "3.0.12"},
+						{ID: "3.1.5", Name: "3.1.5"},
+					}))
+				})
+
+				it("returns the versions newer than a delisted current version by default", func() {
+					resp, err := runVersion(source, &initializr.Version{ID: "3.0.11"})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(check.Response{
+						{ID: "3.0.12", Name: "3.0.12"},
+						{ID: "3.1.5", Name: "3.1.5"},
+					}))
+				})
+
+				when("the current version has been delisted", func() {
+					var logs *bytes.Buffer
+					var current *initializr.Version
+
+					it.Before(func() {
+						logs = new(bytes.Buffer)
+						log.SetOutput(logs)
+
+						current = &initializr.Version{ID: "3.0.11"}
+					})
+
+					it.After(func() {
+						log.SetOutput(os.Stderr)
+					})
+
+					it("warns that it emitted every newer version", func() {
+						Expect(json.Unmarshal([]byte(`{"on_delisted": "newer"}`), &source)).To(Succeed())
+
+						resp, err := runVersion(source, current)
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(HaveLen(2))
+						Expect(logs.String()).To(ContainSubstring("current version 3.0.11 is no longer listed by the Initializr, emitting the 2 listed versions newer than it"))
+					})
 
-						cmd := &check.Command{
-							Client: fakeClient,
-						}
+					it("emits only the latest version with on_delisted: latest", func() {
+						Expect(json.Unmarshal([]byte(`{"on_delisted": "latest"}`), &source)).To(Succeed())
 
-						resp, err := cmd.Run(request)
+						resp, err := runVersion(source, current)
 						Expect(err).NotTo(HaveOccurred())
-						Expect(resp).To(HaveLen(0))
+						Expect(resp).To(Equal(check.Response{{ID: "3.1.5", Name: "3.1.5"}}))
+						Expect(logs.String()).To(ContainSubstring("current version 3.0.11 is no longer listed by the Initializr, emitting the latest version 3.1.5"))
+					})
+
+					it("fails with on_delisted: error", func() {
+						Expect(json.Unmarshal([]byte(`{"on_delisted": "error"}`), &source)).To(Succeed())
+
+						_, err := runVersion(source, current)
+						Expect(err).To(MatchError("current version 3.0.11 is no longer listed by the Initializr"))
+					})
+
+					it("rejects an unknown policy", func() {
+						err := json.Unmarshal([]byte(`{"on_delisted": "ignore"}`), &source)
+						Expect(err).To(MatchError(ContainSubstring("on_delisted must be one of newer, latest or error, got ignore")))
+					})
+				})
+
+				when("the source leaves out a current version that is still listed", func() {
+					var logs *bytes.Buffer
+
+					it.Before(func() {
+						logs = new(bytes.Buffer)
+						log.SetOutput(logs)
+					})
+
+					it.After(func() {
+						log.SetOutput(os.Stderr)
 					})
 
-					it("returns a version with snapshots enabled", func() {
-						bytes, err := ioutil.ReadFile("testdata/subsequent_request_with_pin_and_snapshots.json")
+					it("emits the newer included versions without applying on_delisted", func() {
+						Expect(json.Unmarshal([]byte(`{"on_delisted": "error", "release_channels": ["ga", "milestone"]}`), &source)).To(Succeed())
+
+						resp, err := runVersion(source, &initializr.Version{ID: "3.1.6-SNAPSHOT"})
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(check.Response{{ID: "3.2.0-M3", Name: "3.2.0 (M3)"}}))
+						Expect(logs.String()).To(BeEmpty())
+					})
 
-						err = json.Unmarshal(bytes, &request)
+					it("emits no versions when none of the included ones is newer", func() {
+						Expect(json.Unmarshal([]byte(`{"on_delisted": "error"}`), &source)).To(Succeed())
+
+						resp, err := runVersion(source, &initializr.Version{ID: "3.2.0-RC1"})
 						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(BeEmpty())
+						Expect(logs.String()).To(BeEmpty())
+					})
 
-						cmd := &check.Command{
-							Client: fakeClient,
-						}
+					it("does not treat a version a required dependency rules out as delisted", func() {
+						Expect(json.Unmarshal([]byte(`{"on_delisted": "error", "required_dependencies": ["web", "graphql"]}`), &source)).To(Succeed())
 
-						resp, err := cmd.Run(request)
+						resp, err := runVersion(source, &initializr.Version{ID: "3.1.5"})
 						Expect(err).NotTo(HaveOccurred())
-						Expect(resp).To(HaveLen(1))
-						Expect(resp[0].ID).To(Equal("1.5.14.BUILD-SNAPSHOT"))
+						Expect(resp).To(BeEmpty())
 					})
 				})
+
+				it("returns an empty list rather than null when no version is included", func() {
+					Expect(json.Unmarshal([]byte(`{"version_constraint": ">=4.0.0"}`), &source)).To(Succeed())
+
+					resp, err := runVersion(source, nil)
+					Expect(err).NotTo(HaveOccurred())
+
+					encoded, err := json.Marshal(resp)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(encoded)).To(Equal("[]"))
+				})
+			})
+
+			when("the Initializr lists a version that cannot be parsed", func() {
+				it.Before(func() {
+					startServer("testdata/malformed")
+				})
+
+				it("returns an error instead of panicking", func() {
+					_, err := runRequest("testdata/first_request.json")
+					Expect(err).To(MatchError(ContainSubstring(`"2.0" is not a recognized Spring Boot version`)))
+				})
 			})
 		})
 	}, spec.Report(report.Terminal{}))
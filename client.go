@@ -0,0 +1,243 @@
+package initializr
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Client fetches documents from an Initializr instance. The root metadata and each
+// dependency catalog are fetched at most once and cached for the life of the Client
+type Client struct {
+	HTTPClient *http.Client
+	URL        *url.URL
+
+	mu       sync.Mutex
+	metadata *Metadata
+	catalogs map[string][]byte
+}
+
+// Metadata returns the root metadata document
+func (c *Client) Metadata() (*Metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+
+	doc, err := c.fetch(c.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata Metadata
+	if err = json.Unmarshal(doc, &metadata); err != nil {
+		return nil, fmt.Errorf("decoding metadata from %s: %s", c.URL, err.Error())
+	}
+
+	c.metadata = &metadata
+	return c.metadata, nil
+}
+
+// DependencyCatalog returns the /dependencies document for the given Boot version
+func (c *Client) DependencyCatalog(bootVersion BootVersion) (*DependencyCatalog, error) {
+	doc, err := c.rawDependencyCatalog(bootVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog DependencyCatalog
+	if err = json.Unmarshal(doc, &catalog); err != nil {
+		return nil, fmt.Errorf("decoding dependencies for %s: %s", bootVersion, err.Error())
+	}
+
+	return &catalog, nil
+}
+
+// DependencyCatalogVersion returns a Version whose ID is a digest of the dependency catalog
+// for the given Boot version, so that it changes whenever the catalog does
+func (c *Client) DependencyCatalogVersion(bootVersion BootVersion) (Version, error) {
+	doc, err := c.rawDependencyCatalog(bootVersion)
+	if err != nil {
+		return Version{}, err
+	}
+
+	digest, err := CatalogDigest(doc)
+	if err != nil {
+		return Version{}, err
+	}
+
+	return Version{Name: bootVersion.ID, ID: digest}, nil
+}
+
+// UnavailableDependencies returns the required dependency IDs whose versionRange excludes
+// the given Boot version, in the order they were given. IDs the Initializr does not list
+// are an error
+func (c *Client) UnavailableDependencies(required []string, bootVersion BootVersion) ([]string, error) {
+	if len(required) == 0 {
+		return nil, nil
+	}
+
+	metadata, err := c.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	var unknown []string
+	for _, id := range required {
+		if _, _, ok := metadata.Dependency(id); !ok {
+			unknown = append(unknown, id)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("required dependencies %s are not known to the Initializr; valid IDs are %s", strings.Join(unknown, ", "), strings.Join(metadata.DependencyIDs(), ", "))
+	}
+
+	var unavailable []string
+	for _, id := range required {
+		dep, _, _ := metadata.Dependency(id)
+		available, err := dep.Available(bootVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		if !available {
+			unavailable = append(unavailable, id)
+		}
+	}
+
+	return unavailable, nil
+}
+
+// ProjectURL resolves the URL that generates a project of the given type by expanding the
+// templated link the Initializr advertises for it. params are keyed by the names used in the
+// template (dependencies, packaging, javaVersion, bootVersion, ...) and ones the template
+// does not declare are ignored
+func (c *Client) ProjectURL(projectType string, params map[string]string) (*url.URL, error) {
+	metadata, err := c.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	option, ok := metadata.Type.Option(projectType)
+	if !ok {
+		return nil, fmt.Errorf("unknown project type %q, valid types are: %s", projectType, strings.Join(metadata.Type.IDs(), ", "))
+	}
+
+	if links := metadata.Links[projectType]; len(links) > 0 {
+		href := links[0].Href
+		if links[0].Templated {
+			if href, err = ExpandURITemplate(href, params); err != nil {
+				return nil, fmt.Errorf("expanding link for %s: %s", projectType, err.Error())
+			}
+		}
+
+		return c.URL.Parse(href)
+	}
+
+	// older Initializr instances only advertise the action on the type field itself
+	if option.Action == "" {
+		return nil, fmt.Errorf("the Initializr does not advertise an endpoint for project type %q", projectType)
+	}
+
+	query := url.Values{"type": {projectType}}
+	for key, val := range params {
+		query.Set(key, val)
+	}
+
+	return endpoint(c.URL, option.Action, query), nil
+}
+
+// Generate downloads the project or build file of the given type. See ProjectURL for params
+func (c *Client) Generate(projectType string, params map[string]string) ([]byte, error) {
+	u, err := c.ProjectURL(projectType, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Download(u)
+}
+
+// Download fetches a generated project or build file from a URL such as one returned by
+// ProjectURL
+func (c *Client) Download(u *url.URL) ([]byte, error) {
+	return c.get(u, "*/*")
+}
+
+func (c *Client) rawDependencyCatalog(bootVersion BootVersion) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if doc, ok := c.catalogs[bootVersion.ID]; ok {
+		return doc, nil
+	}
+
+	doc, err := c.fetch(endpoint(c.URL, "dependencies", url.Values{"bootVersion": {bootVersion.ID}}))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.catalogs == nil {
+		c.catalogs = make(map[string][]byte)
+	}
+
+	c.catalogs[bootVersion.ID] = doc
+	return doc, nil
+}
+
+func (c *Client) fetch(u *url.URL) ([]byte, error) {
+	return c.get(u, AcceptHeader)
+}
+
+func (c *Client) get(u *url.URL, accept string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", accept)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: expected status 200, got %s", u, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// CatalogDigest returns a sha256 digest of a JSON document that does not depend on key
+// order or whitespace
+func CatalogDigest(doc []byte) (string, error) {
+	var parsed interface{}
+	if err := json.Unmarshal(doc, &parsed); err != nil {
+		return "", err
+	}
+
+	// encoding/json writes map keys in sorted order, so this is canonical
+	canonical, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(canonical)), nil
+}
+
+func endpoint(base *url.URL, p string, query url.Values) *url.URL {
+	u := *base
+	u.Path = path.Join("/", u.Path, p)
+	u.RawQuery = query.Encode()
+
+	return &u
+}
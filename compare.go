@@ -0,0 +1,187 @@
+package initializr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Kinds of CoordinateChange
+const (
+	ChangedDependency = "dependency"
+	ChangedBOM        = "bom"
+	ChangedRepository = "repository"
+	ChangedParent     = "parent"
+	ChangedPlugin     = "plugin"
+	ChangedProperty   = "property"
+)
+
+// CoordinateChange is one difference between the dependency catalogs of two Boot versions.
+// From is empty for additions and To is empty for removals
+type CoordinateChange struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// CatalogComparison summarizes what changed in the dependency catalog between two Boot versions
+type CatalogComparison struct {
+	FromBootVersion string             `json:"fromBootVersion"`
+	ToBootVersion   string             `json:"toBootVersion"`
+	Changes         []CoordinateChange `json:"changes"`
+}
+
+// CompareCatalogs lists the dependencies, BOMs and repositories whose coordinates differ
+// between two catalogs, sorted by kind and ID
+func CompareCatalogs(from, to *DependencyCatalog) CatalogComparison {
+	comparison := CatalogComparison{
+		FromBootVersion: from.BootVersion,
+		ToBootVersion:   to.BootVersion,
+		Changes:         []CoordinateChange{},
+	}
+
+	comparison.Changes = append(comparison.Changes, compareCoordinates(ChangedDependency, dependencyCoordinates(from), dependencyCoordinates(to))...)
+	comparison.Changes = append(comparison.Changes, compareCoordinates(ChangedBOM, bomCoordinates(from), bomCoordinates(to))...)
+	comparison.Changes = append(comparison.Changes, compareCoordinates(ChangedRepository, repositoryURLs(from), repositoryURLs(to))...)
+	sortChanges(comparison.Changes)
+
+	return comparison
+}
+
+// ProjectComparison summarizes what changed in a generated project between two Boot versions
+type ProjectComparison struct {
+	FromBootVersion string             `json:"fromBootVersion"`
+	ToBootVersion   string             `json:"toBootVersion"`
+	BuildFile       string             `json:"buildFile"`
+	Build           []CoordinateChange `json:"build"`
+	Catalog         []CoordinateChange `json:"catalog"`
+}
+
+// CompareBuildFiles lists the parent, versioned plugins, properties and imported BOMs that
+// differ between two versions of the same generated build file, sorted by kind and ID
+func CompareBuildFiles(name string, from, to []byte) ([]CoordinateChange, error) {
+	before, err := buildCoordinates(name, from)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := buildCoordinates(name, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []CoordinateChange{}
+	for _, kind := range []string{ChangedParent, ChangedPlugin, ChangedProperty, ChangedBOM} {
+		changes = append(changes, compareCoordinates(kind, before[kind], after[kind])...)
+	}
+
+	sortChanges(changes)
+	return changes, nil
+}
+
+// buildCoordinates returns the versions declared in a build file, by kind and then by
+// groupId:artifactId, plugin ID or property name
+func buildCoordinates(name string, contents []byte) (map[string]map[string]string, error) {
+	coordinates := map[string]map[string]string{
+		ChangedParent:   {},
+		ChangedPlugin:   {},
+		ChangedProperty: {},
+		ChangedBOM:      {},
+	}
+
+	switch name {
+	case "pom.xml":
+		pom, err := ParsePOM(contents)
+		if err != nil {
+			return nil, err
+		}
+
+		if pom.Parent != nil {
+			coordinates[ChangedParent][pom.Parent.GroupID+":"+pom.Parent.ArtifactID] = pom.Parent.Version
+		}
+
+		for _, plugin := range pom.Plugins {
+			if plugin.Version != "" {
+				coordinates[ChangedPlugin][plugin.GroupID+":"+plugin.ArtifactID] = plugin.Version
+			}
+		}
+
+		for key, val := range pom.Properties {
+			coordinates[ChangedProperty][key] = val
+		}
+
+		for _, bom := range pom.BOMImports() {
+			coordinates[ChangedBOM][bom.GroupID+":"+bom.ArtifactID] = bom.Version
+		}
+	case "build.gradle", "build.gradle.kts":
+		build := ParseGradleBuild(contents)
+		for _, plugin := range build.Plugins {
+			if plugin.Version != "" {
+				coordinates[ChangedPlugin][plugin.ID] = plugin.Version
+			}
+		}
+
+		for _, bom := range build.BOMs {
+			coordinates[ChangedBOM][bom.GroupID+":"+bom.ArtifactID] = bom.Version
+		}
+	default:
+		return nil, fmt.Errorf("%s is not a pom.xml or build.gradle(.kts)", name)
+	}
+
+	return coordinates, nil
+}
+
+func compareCoordinates(kind string, before, after map[string]string) []CoordinateChange {
+	var changes []CoordinateChange
+	for id, value := range before {
+		if after[id] != value {
+			changes = append(changes, CoordinateChange{Kind: kind, ID: id, From: value, To: after[id]})
+		}
+	}
+
+	for id, value := range after {
+		if _, ok := before[id]; !ok {
+			changes = append(changes, CoordinateChange{Kind: kind, ID: id, To: value})
+		}
+	}
+
+	return changes
+}
+
+func sortChanges(changes []CoordinateChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		return a.ID < b.ID
+	})
+}
+
+func dependencyCoordinates(catalog *DependencyCatalog) map[string]string {
+	coordinates := make(map[string]string, len(catalog.Dependencies))
+	for id, dep := range catalog.Dependencies {
+		coordinates[id] = dep.String()
+	}
+
+	return coordinates
+}
+
+func bomCoordinates(catalog *DependencyCatalog) map[string]string {
+	coordinates := make(map[string]string, len(catalog.BOMs))
+	for id, bom := range catalog.BOMs {
+		coordinates[id] = bom.String()
+	}
+
+	return coordinates
+}
+
+func repositoryURLs(catalog *DependencyCatalog) map[string]string {
+	urls := make(map[string]string, len(catalog.Repositories))
+	for id, repo := range catalog.Repositories {
+		urls[id] = repo.URL
+	}
+
+	return urls
+}
@@ -0,0 +1,180 @@
+package initializr
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff returns a unified diff, as produced by diff -u, that turns from into to. It is
+// empty if they are identical
+func UnifiedDiff(fromName, toName string, from, to []byte) string {
+	a, b := splitLines(string(from)), splitLines(string(to))
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// a hunk absorbs following changes until a run of unchanged lines is long enough
+		// to separate two hunks that each have their full context
+		last := i
+		for j := i + 1; j < len(ops); {
+			if ops[j].kind != ' ' {
+				last = j
+				j++
+				continue
+			}
+
+			k := j
+			for k < len(ops) && ops[k].kind == ' ' {
+				k++
+			}
+
+			if k == len(ops) || k-j > 2*diffContext {
+				break
+			}
+
+			j = k
+		}
+
+		start, end := i-diffContext, last+1+diffContext
+		if start < 0 {
+			start = 0
+		}
+
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+
+		writeHunk(&out, ops, start, end)
+		i = end
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, ops []diffOp, start, end int) {
+	fromLine, toLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			fromLine++
+		}
+
+		if op.kind != '-' {
+			toLine++
+		}
+	}
+
+	fromCount, toCount := 0, 0
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			fromCount++
+		}
+
+		if op.kind != '-' {
+			toCount++
+		}
+	}
+
+	// diff -u numbers an empty side from the line before it
+	if fromCount == 0 {
+		fromLine--
+	}
+
+	if toCount == 0 {
+		toLine--
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount))
+	for _, op := range ops[start:end] {
+		out.WriteByte(op.kind)
+		out.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(line, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines computes a shortest edit script between a and b from their longest common
+// subsequence. Build files are small enough that the quadratic table is not a concern
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
@@ -0,0 +1,144 @@
+package initializr
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of Drift
+const (
+	DriftParent      = "parent"
+	DriftPlugin      = "plugin"
+	DriftBOM         = "bom"
+	DriftJavaVersion = "java_version"
+)
+
+// DriftKinds are every kind of Drift, in the order they are reported
+var DriftKinds = []string{DriftParent, DriftPlugin, DriftBOM, DriftJavaVersion}
+
+// Drift is one way an existing build differs from the build the Initializr generates. Expected
+// is the value in the generated build and Actual is the value in the existing one, which is
+// empty if it is missing
+type Drift struct {
+	Kind     string `json:"kind"`
+	ID       string `json:"id"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// DriftReport lists how an existing build differs from a freshly generated one
+type DriftReport struct {
+	BootVersion string  `json:"bootVersion"`
+	BuildFile   string  `json:"buildFile"`
+	Drift       []Drift `json:"drift"`
+}
+
+var (
+	gradleJavaVersionPattern = regexp.MustCompile(`(?m)^[ \t]*(?:java\.)?sourceCompatibility[ \t]*=[ \t]*(?:JavaVersion\.VERSION_|["']|JavaVersion\.toVersion\(["']?)?([0-9][0-9._]*)`)
+	gradleToolchainPattern   = regexp.MustCompile(`JavaLanguageVersion\.of\([ \t]*["']?([0-9]+)`)
+)
+
+// DetectDrift compares an existing build file to the one the Initializr generated and reports
+// a parent or Java version that differs, and generated plugins and BOM imports that are
+// missing or have a different version. Anything else the existing build declares is its own
+// business and is not reported. Both builds must use the same build file
+func DetectDrift(bootVersion, buildFile string, generated, existing []byte) (DriftReport, error) {
+	expected, err := buildCoordinates(buildFile, generated)
+	if err != nil {
+		return DriftReport{}, err
+	}
+
+	actual, err := buildCoordinates(buildFile, existing)
+	if err != nil {
+		return DriftReport{}, err
+	}
+
+	report := DriftReport{BootVersion: bootVersion, BuildFile: buildFile, Drift: []Drift{}}
+	for _, kind := range []string{DriftParent, DriftPlugin, DriftBOM} {
+		var drift []Drift
+		for id, value := range expected[kind] {
+			if actual[kind][id] != value {
+				drift = append(drift, Drift{Kind: kind, ID: id, Expected: value, Actual: actual[kind][id]})
+			}
+		}
+
+		sort.Slice(drift, func(i, j int) bool {
+			return drift[i].ID < drift[j].ID
+		})
+
+		report.Drift = append(report.Drift, drift...)
+	}
+
+	expectedJava, actualJava := javaVersion(buildFile, generated), javaVersion(buildFile, existing)
+	if expectedJava != "" && expectedJava != actualJava {
+		report.Drift = append(report.Drift, Drift{Kind: DriftJavaVersion, ID: "java", Expected: expectedJava, Actual: actualJava})
+	}
+
+	return report, nil
+}
+
+// Exceeds returns the drift whose kind is one of kinds, or all of it if kinds contains "any"
+func (r DriftReport) Exceeds(kinds []string) []Drift {
+	var exceeding []Drift
+	for _, d := range r.Drift {
+		for _, kind := range kinds {
+			if kind == d.Kind || kind == "any" {
+				exceeding = append(exceeding, d)
+				break
+			}
+		}
+	}
+
+	return exceeding
+}
+
+// Summary describes the drift in a line per difference, for people to read
+func (r DriftReport) Summary() string {
+	if len(r.Drift) == 0 {
+		return fmt.Sprintf("%s matches the Spring Boot %s skeleton\n", r.BuildFile, r.BootVersion)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s has drifted from the Spring Boot %s skeleton in %d places:\n", r.BuildFile, r.BootVersion, len(r.Drift))
+	for _, d := range r.Drift {
+		out.WriteString(d.String())
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+func (d Drift) String() string {
+	if d.Actual == "" {
+		return fmt.Sprintf("  %s %s: missing, expected %s", d.Kind, d.ID, d.Expected)
+	}
+
+	return fmt.Sprintf("  %s %s: %s, expected %s", d.Kind, d.ID, d.Actual, d.Expected)
+}
+
+// javaVersion returns the Java version a build targets, e.g. 1.8 or 17
+func javaVersion(buildFile string, contents []byte) string {
+	if buildFile == "pom.xml" {
+		pom, err := ParsePOM(contents)
+		if err != nil {
+			return ""
+		}
+
+		return pom.Properties["java.version"]
+	}
+
+	// current builds declare a toolchain, e.g. languageVersion = JavaLanguageVersion.of(17)
+	if m := gradleToolchainPattern.FindSubmatch(contents); m != nil {
+		return string(m[1])
+	}
+
+	m := gradleJavaVersionPattern.FindSubmatch(contents)
+	if m == nil {
+		return ""
+	}
+
+	// JavaVersion.VERSION_1_8 is 1.8
+	return strings.Replace(string(m[1]), "_", ".", -1)
+}
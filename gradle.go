@@ -0,0 +1,126 @@
+package initializr
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GradlePlugin is a plugin applied in the plugins block of a Gradle build
+type GradlePlugin struct {
+	ID      string `json:"id"`
+	Version string `json:"version,omitempty"`
+}
+
+// GradleDependency is a dependency declared with string notation, e.g. implementation 'g:a:v'
+type GradleDependency struct {
+	Configuration string `json:"configuration"`
+	GroupID       string `json:"groupId"`
+	ArtifactID    string `json:"artifactId"`
+	Version       string `json:"version,omitempty"`
+}
+
+// GradleBuild is what can be read from a Gradle build script as the Initializr generates it,
+// in either the Groovy or the Kotlin DSL. It is not a general Gradle parser
+type GradleBuild struct {
+	Plugins      []GradlePlugin     `json:"plugins"`
+	Dependencies []GradleDependency `json:"dependencies"`
+	BOMs         []GradleDependency `json:"boms"`
+}
+
+var (
+	gradlePluginPattern     = regexp.MustCompile(`(?m)^[ \t]*(id|kotlin)[ \t]*\(?[ \t]*["']([^"']+)["'](?:[ \t]*\))?(?:[ \t]+version[ \t]*\(?[ \t]*["']([^"']+)["'][ \t]*\)?)?`)
+	gradleDependencyPattern = regexp.MustCompile(`(?m)^[ \t]*([A-Za-z]+)[ \t]*\(?[ \t]*["']([^"':\s]+):([^"':\s]+)(?::([^"'\s]+))?["'][ \t]*\)?[ \t]*$`)
+	gradleBOMPattern        = regexp.MustCompile(`(?m)^[ \t]*mavenBom[ \t]*\(?[ \t]*["'](.*)["'][ \t]*\)?[ \t]*$`)
+	gradlePropertyPattern   = regexp.MustCompile(`(?m)^[ \t]*(?:set[ \t]*\([ \t]*["']([^"']+)["'][ \t]*,|extra[ \t]*\[[ \t]*"([^"]+)"[ \t]*\][ \t]*=)[ \t]*["']([^"']+)["']`)
+	gradleExtVersionPattern = regexp.MustCompile(`(?m)^[ \t]*([A-Za-z][A-Za-z0-9]*Version)[ \t]*=[ \t]*["']([^"']+)["']`)
+	gradleApplyPattern      = regexp.MustCompile(`(?m)^[ \t]*apply[ \t]+plugin[ \t]*:[ \t]*["']([^"']+)["']`)
+	gradleReferencePattern  = regexp.MustCompile(`\$\{\s*(?:property\s*\(\s*"([^"]+)"\s*\)|([A-Za-z_][A-Za-z0-9_]*))\s*\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// ParseGradleBuild reads the plugins, dependencies and imported BOMs of a build.gradle or
+// build.gradle.kts. Versions that refer to ext properties set in the same file are resolved
+func ParseGradleBuild(contents []byte) *GradleBuild {
+	script := string(contents)
+	build := &GradleBuild{}
+
+	// Groovy uses ext { set('name', "value") } and Kotlin uses extra["name"] = "value"
+	properties := make(map[string]string)
+	for _, m := range gradlePropertyPattern.FindAllStringSubmatch(script, -1) {
+		properties[m[1]+m[2]] = m[3]
+	}
+
+	// builds generated before Spring Boot 2.1 declare versions as ext { springBootVersion = '...' }
+	for _, m := range gradleExtVersionPattern.FindAllStringSubmatch(script, -1) {
+		properties[m[1]] = m[2]
+	}
+
+	resolve := func(s string) string {
+		return gradleReferencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			m := gradleReferencePattern.FindStringSubmatch(ref)
+			if val, ok := properties[m[1]+m[2]+m[3]]; ok {
+				return val
+			}
+
+			return ref
+		})
+	}
+
+	for _, m := range gradlePluginPattern.FindAllStringSubmatch(script, -1) {
+		id := m[2]
+		if m[1] == "kotlin" {
+			id = "org.jetbrains.kotlin." + id
+		}
+
+		build.Plugins = append(build.Plugins, GradlePlugin{ID: id, Version: resolve(m[3])})
+	}
+
+	// and apply the Boot plugin, whose version is on the buildscript classpath, by ID
+	for _, m := range gradleApplyPattern.FindAllStringSubmatch(script, -1) {
+		plugin := GradlePlugin{ID: m[1]}
+		if plugin.ID == "org.springframework.boot" {
+			plugin.Version = properties["springBootVersion"]
+		}
+
+		build.Plugins = append(build.Plugins, plugin)
+	}
+
+	for _, m := range gradleBOMPattern.FindAllStringSubmatch(script, -1) {
+		parts := strings.SplitN(resolve(m[1]), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		build.BOMs = append(build.BOMs, GradleDependency{
+			Configuration: "mavenBom",
+			GroupID:       parts[0],
+			ArtifactID:    parts[1],
+			Version:       parts[2],
+		})
+	}
+
+	for _, m := range gradleDependencyPattern.FindAllStringSubmatch(script, -1) {
+		if m[1] == "id" || m[1] == "mavenBom" {
+			continue
+		}
+
+		build.Dependencies = append(build.Dependencies, GradleDependency{
+			Configuration: m[1],
+			GroupID:       m[2],
+			ArtifactID:    m[3],
+			Version:       resolve(m[4]),
+		})
+	}
+
+	return build
+}
+
+// Plugin returns the plugin with the given ID
+func (g *GradleBuild) Plugin(id string) (GradlePlugin, bool) {
+	for _, plugin := range g.Plugins {
+		if plugin.ID == id {
+			return plugin, true
+		}
+	}
+
+	return GradlePlugin{}, false
+}
@@ -0,0 +1,128 @@
+package in
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/jghiloni/spring-initializr-resource"
+)
+
+// comparePrevious compares against the newest older Boot version of the same release type
+const comparePrevious = "previous"
+
+// compareVersion resolves the compare_to param to a Boot version
+func compareVersion(client *initializr.Client, compareTo string, current initializr.BootVersion) (initializr.BootVersion, error) {
+	if compareTo != comparePrevious {
+		return initializr.ParseBootVersion(compareTo)
+	}
+
+	metadata, err := client.Metadata()
+	if err != nil {
+		return initializr.BootVersion{}, err
+	}
+
+	versions, err := metadata.BootVersions()
+	if err != nil {
+		return initializr.BootVersion{}, err
+	}
+
+	previous, ok := versions.Previous(current)
+	if !ok {
+		return initializr.BootVersion{}, fmt.Errorf("the Initializr does not list a Spring Boot version before %s to compare to", current)
+	}
+
+	return previous, nil
+}
+
+// compare generates the same project for the compare_to version and writes compare.diff, a
+// unified diff of the two build files, and compare.json, the coordinates that changed in the
+// build file and the dependency catalog. It returns the version compared to
+func (command *Command) compare(client *initializr.Client, destinationDir, projectType string, queryParams map[string]string, request Request, artifactPath string) (string, error) {
+	current, err := initializr.ParseBootVersion(request.Version.ID)
+	if err != nil {
+		return "", err
+	}
+
+	from, err := compareVersion(client, request.Params.CompareTo, current)
+	if err != nil {
+		return "", err
+	}
+
+	params := map[string]string{"bootVersion": from.ID}
+	for key, val := range queryParams {
+		if key != "bootVersion" {
+			params[key] = val
+		}
+	}
+
+	fromURL, err := client.ProjectURL(projectType, params)
+	if err != nil {
+		return "", err
+	}
+
+	if !empty(request.Params.ArchiveFormat) {
+		if fromURL, err = initializr.WithArchiveFormat(fromURL, request.Params.ArchiveFormat); err != nil {
+			return "", err
+		}
+	}
+
+	body, err := client.Download(fromURL)
+	if err != nil {
+		return "", fmt.Errorf("generating the project for %s: %s", from, err.Error())
+	}
+
+	// the other project is only needed long enough to read its build file
+	tmpDir, err := ioutil.TempDir("", "compare")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	fromPath := filepath.Join(tmpDir, path.Base(fromURL.Path))
+	if err = ioutil.WriteFile(fromPath, body, 0644); err != nil {
+		return "", err
+	}
+
+	_, fromBuild, err := initializr.ReadBuildFile(fromPath)
+	if err != nil {
+		return "", err
+	}
+
+	buildFile, toBuild, err := initializr.ReadBuildFile(artifactPath)
+	if err != nil {
+		return "", err
+	}
+
+	diff := initializr.UnifiedDiff(path.Join("a", from.ID, buildFile), path.Join("b", current.ID, buildFile), fromBuild, toBuild)
+	if err = ioutil.WriteFile(filepath.Join(destinationDir, "compare.diff"), []byte(diff), 0644); err != nil {
+		return "", err
+	}
+
+	buildChanges, err := initializr.CompareBuildFiles(buildFile, fromBuild, toBuild)
+	if err != nil {
+		return "", err
+	}
+
+	fromCatalog, err := client.DependencyCatalog(from)
+	if err != nil {
+		return "", err
+	}
+
+	toCatalog, err := client.DependencyCatalog(current)
+	if err != nil {
+		return "", err
+	}
+
+	comparison := initializr.ProjectComparison{
+		FromBootVersion: from.ID,
+		ToBootVersion:   current.ID,
+		BuildFile:       buildFile,
+		Build:           buildChanges,
+		Catalog:         initializr.CompareCatalogs(fromCatalog, toCatalog).Changes,
+	}
+
+	return from.ID, writeJSON(filepath.Join(destinationDir, "compare.json"), comparison)
+}
@@ -0,0 +1,110 @@
+package in
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/jghiloni/spring-initializr-resource"
+)
+
+// projectDir resolves the project param. Relative paths are resolved against the directory
+// the destination is in, so that a project checked out next to it can be named directly
+func projectDir(destinationDir, project string) string {
+	if filepath.IsAbs(project) {
+		return project
+	}
+
+	return filepath.Join(filepath.Dir(destinationDir), project)
+}
+
+// readProject returns the build file of the existing project, which must be the same kind
+// of build file as the generated one
+func readProject(destinationDir, project, buildFile string) ([]byte, error) {
+	dir := projectDir(destinationDir, project)
+	name, err := initializr.FindBuildFile(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != buildFile {
+		return nil, fmt.Errorf("project %s has a %s, but the generated project has a %s", project, name, buildFile)
+	}
+
+	return ioutil.ReadFile(filepath.Join(dir, name))
+}
+
+// validateProjectParams checks that the params that need project have it and that
+// fail_on_drift only names kinds of drift
+func validateProjectParams(params Params) error {
+	if len(params.FailOnDrift) > 0 && empty(params.Project) {
+		return fmt.Errorf("fail_on_drift requires project")
+	}
+
+	if params.UpgradePatch && empty(params.Project) {
+		return fmt.Errorf("upgrade_patch requires project")
+	}
+
+	for _, kind := range params.FailOnDrift {
+		valid := kind == "any"
+		for _, driftKind := range initializr.DriftKinds {
+			valid = valid || kind == driftKind
+		}
+
+		if !valid {
+			return fmt.Errorf("fail_on_drift must only contain any, %s, got %s", strings.Join(initializr.DriftKinds, ", "), kind)
+		}
+	}
+
+	return nil
+}
+
+// compareProject compares the project param's build file to the generated one and writes
+// drift.json and drift.txt, and upgrade.patch if it was asked for
+func (command *Command) compareProject(destinationDir string, request Request, artifactPath string) (initializr.DriftReport, error) {
+	buildFile, generated, err := initializr.ReadBuildFile(artifactPath)
+	if err != nil {
+		return initializr.DriftReport{}, err
+	}
+
+	existing, err := readProject(destinationDir, request.Params.Project, buildFile)
+	if err != nil {
+		return initializr.DriftReport{}, err
+	}
+
+	report, err := initializr.DetectDrift(request.Version.ID, buildFile, generated, existing)
+	if err != nil {
+		return initializr.DriftReport{}, err
+	}
+
+	if err = writeJSON(filepath.Join(destinationDir, "drift.json"), report); err != nil {
+		return initializr.DriftReport{}, err
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(destinationDir, "drift.txt"), []byte(report.Summary()), 0644); err != nil {
+		return initializr.DriftReport{}, err
+	}
+
+	if !request.Params.UpgradePatch {
+		return report, nil
+	}
+
+	// the patch applies to the project directory with patch -p1 or git apply
+	upgraded, err := initializr.MergeBuildFile(buildFile, generated, existing)
+	if err != nil {
+		return initializr.DriftReport{}, err
+	}
+
+	patch := initializr.UnifiedDiff("a/"+buildFile, "b/"+buildFile, existing, upgraded)
+	return report, ioutil.WriteFile(filepath.Join(destinationDir, "upgrade.patch"), []byte(patch), 0644)
+}
+
+func driftLines(drift []initializr.Drift) string {
+	lines := make([]string, 0, len(drift))
+	for _, d := range drift {
+		lines = append(lines, d.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -1,17 +1,19 @@
 package in
 
 import (
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/jghiloni/spring-initializr-resource"
+	"gopkg.in/yaml.v2"
 )
 
 // Command will perform the in operation and download the appropriate artifacts for a given version
@@ -23,66 +25,137 @@ var emptyResponse = Response{}
 
 // Run is the main unit of work for the Command
 func (command *Command) Run(destinationDir string, request Request) (Response, error) {
-	if err := os.MkdirAll(destinationDir, 0755); err != nil {
+	// projects are generated for the Boot version, and the version is given back as it came
+	version := request.Version
+	bootVersion := version.BootVersion()
+	request.Version = initializr.Version{Name: bootVersion, ID: bootVersion}
+
+	// relative project paths are resolved against the destination, so it must not move
+	destinationDir, err := filepath.Abs(destinationDir)
+	if err != nil {
 		return emptyResponse, err
 	}
 
-	queryParams := url.Values{}
-	setValueOrDefault(&queryParams, "type", request.Params.Type, "maven-project")
-	setValue(&queryParams, "packaging", request.Params.Packaging)
-	setValue(&queryParams, "language", request.Params.Language)
-	setValue(&queryParams, "dependencies", request.Params.Dependencies)
-	setValue(&queryParams, "javaVersion", request.Params.JDKVersion)
-	setValue(&queryParams, "bootVersion", request.Version.ID)
-	setValue(&queryParams, "groupId", request.Params.GroupID)
-	setValue(&queryParams, "artifactId", request.Params.ArtifactID)
-	setValue(&queryParams, "version", request.Params.Version)
-	setValue(&queryParams, "name", request.Params.Name)
-	setValue(&queryParams, "description", request.Params.Description)
-	setValue(&queryParams, "packageName", request.Params.PackageName)
+	client := &initializr.Client{HTTPClient: command.Client, URL: request.Source.URL}
+
+	var response Response
+	if len(request.Params.Variants) > 0 {
+		response, err = command.runVariants(client, destinationDir, request)
+	} else {
+		response, err = command.generate(client, destinationDir, request)
+	}
+
+	if err != nil {
+		return emptyResponse, err
+	}
+
+	response.Version = version
+	return response, nil
+}
+
+// generate downloads a single project into destinationDir
+func (command *Command) generate(client *initializr.Client, destinationDir string, request Request) (Response, error) {
+	if err := os.MkdirAll(destinationDir, 0755); err != nil {
+		return emptyResponse, err
+	}
 
-	targetURL := request.Source.URL
+	projectType := request.Params.Type
+	if empty(projectType) {
+		projectType = "maven-project"
+	}
 
-	endpoint := ""
-	switch request.Params.Type {
-	case "maven-project", "gradle-project":
-		endpoint = "/starter.zip"
-	case "maven-build":
-		endpoint = "/pom.xml"
-	case "gradle-build":
-		endpoint = "/build.gradle"
+	queryParams := make(map[string]string)
+	setValue(queryParams, "packaging", request.Params.Packaging)
+	setValue(queryParams, "language", request.Params.Language)
+	setValue(queryParams, "dependencies", request.Params.Dependencies)
+	setValue(queryParams, "javaVersion", request.Params.JDKVersion)
+	setValue(queryParams, "bootVersion", request.Version.ID)
+	setValue(queryParams, "groupId", request.Params.GroupID)
+	setValue(queryParams, "artifactId", request.Params.ArtifactID)
+	setValue(queryParams, "version", request.Params.Version)
+	setValue(queryParams, "name", request.Params.Name)
+	setValue(queryParams, "description", request.Params.Description)
+	setValue(queryParams, "packageName", request.Params.PackageName)
+
+	if err := validateParams(client, projectType, queryParams); err != nil {
+		return emptyResponse, err
 	}
 
-	targetURL.Path = path.Join(targetURL.Path, endpoint)
-	targetURL.RawQuery = queryParams.Encode()
+	if err := validateProjectParams(request.Params); err != nil {
+		return emptyResponse, err
+	}
 
-	httpRequest, err := http.NewRequest("GET", targetURL.String(), nil)
+	// the endpoint for each project type comes from the templated links in the metadata
+	targetURL, err := client.ProjectURL(projectType, queryParams)
 	if err != nil {
 		return emptyResponse, err
 	}
 
-	httpRequest.Header.Add("Accept", initializr.AcceptHeader)
-	httpResponse, err := command.Client.Do(httpRequest)
+	if !empty(request.Params.ArchiveFormat) {
+		if targetURL, err = initializr.WithArchiveFormat(targetURL, request.Params.ArchiveFormat); err != nil {
+			return emptyResponse, err
+		}
+	}
+
+	respBody, err := client.Download(targetURL)
 	if err != nil {
 		return emptyResponse, err
 	}
 
-	respBody, err := ioutil.ReadAll(httpResponse.Body)
+	fileName := path.Base(targetURL.Path)
+	artifactPath := filepath.Join(destinationDir, fileName)
+
+	err = ioutil.WriteFile(artifactPath, respBody, 0644)
 	if err != nil {
 		return emptyResponse, err
 	}
 
-	if httpResponse.StatusCode != 200 {
-		return emptyResponse, fmt.Errorf("Expected 200 OK, got %d %s with message %s", httpResponse.StatusCode, httpResponse.Status, string(respBody))
+	comparedTo := ""
+	if !empty(request.Params.CompareTo) {
+		if comparedTo, err = command.compare(client, destinationDir, projectType, queryParams, request, artifactPath); err != nil {
+			return emptyResponse, err
+		}
 	}
 
-	fileName := path.Base(targetURL.Path)
+	if request.Params.Normalize && isArchive(fileName) {
+		if err = initializr.NormalizeArchive(artifactPath); err != nil {
+			return emptyResponse, fmt.Errorf("normalizing %s: %s", fileName, err.Error())
+		}
+
+		// the digests describe the archive as it was left in the destination
+		if respBody, err = ioutil.ReadFile(artifactPath); err != nil {
+			return emptyResponse, err
+		}
+	}
 
-	err = ioutil.WriteFile(filepath.Join(destinationDir, fileName), respBody, 0644)
+	// the build file is read before unpacking, which may remove the archive
+	pom, err := writeProject(destinationDir, artifactPath)
 	if err != nil {
 		return emptyResponse, err
 	}
 
+	var drift *initializr.DriftReport
+	if !empty(request.Params.Project) {
+		report, err := command.compareProject(destinationDir, request, artifactPath)
+		if err != nil {
+			return emptyResponse, err
+		}
+
+		drift = &report
+	}
+
+	if request.Params.OfflineManifest {
+		if err = command.writeOfflineManifest(client, destinationDir, request, artifactPath); err != nil {
+			return emptyResponse, err
+		}
+	}
+
+	if request.Params.Unpack {
+		if err = unpack(destinationDir, fileName, request.Params); err != nil {
+			return emptyResponse, err
+		}
+	}
+
 	if err = ioutil.WriteFile(filepath.Join(destinationDir, "version"), []byte(request.Version.ID), 0644); err != nil {
 		return emptyResponse, err
 	}
@@ -91,98 +164,239 @@ func (command *Command) Run(destinationDir string, request Request) (Response, e
 		return emptyResponse, err
 	}
 
-	if err = command.writeDependencies(destinationDir, request); err != nil {
+	if err = command.writeDependencies(client, destinationDir, request); err != nil {
 		return emptyResponse, err
 	}
 
-	return Response{
-		Version: request.Version,
-		Metadata: []initializr.MetadataPair{
-			initializr.MetadataPair{
-				Name:  "file",
-				Value: fileName,
-			},
-			initializr.MetadataPair{
-				Name:  "version",
-				Value: request.Version.ID,
-			},
+	// the manifest is written last so that it describes everything in the destination
+	artifact := fileName
+	if request.Params.Unpack && !request.Params.KeepArchive {
+		artifact = ""
+	}
+
+	if _, err = initializr.WriteManifest(destinationDir, artifact, request.Params.SHA512); err != nil {
+		return emptyResponse, err
+	}
+
+	metadata := []initializr.MetadataPair{
+		initializr.MetadataPair{
+			Name:  "file",
+			Value: fileName,
 		},
+		initializr.MetadataPair{
+			Name:  "version",
+			Value: request.Version.ID,
+		},
+	}
+
+	if pom != nil {
+		metadata = append(metadata, pom.MetadataPairs()...)
+	}
+
+	if drift != nil {
+		metadata = append(metadata, initializr.MetadataPair{Name: "drift", Value: fmt.Sprint(len(drift.Drift))})
+
+		// the report is still written so that it can be read when the get fails
+		if exceeding := drift.Exceeds(request.Params.FailOnDrift); len(exceeding) > 0 {
+			return emptyResponse, fmt.Errorf("%d differences from the generated build are not allowed by fail_on_drift:\n%s", len(exceeding), driftLines(exceeding))
+		}
+	}
+
+	if comparedTo != "" {
+		metadata = append(metadata, initializr.MetadataPair{Name: "compared_to", Value: comparedTo})
+	}
+
+	return Response{
+		Version:  request.Version,
+		Metadata: append(metadata, artifactDigests(respBody, request.Params.SHA512)...),
 	}, nil
 }
 
-func (command *Command) writeDependencies(destDir string, request Request) error {
-	depResponse := struct {
-		Dependencies map[string]interface{} `json:"dependencies,omitempty"`
-		BOMs         map[string]interface{} `json:"boms,omitempty"`
-	}{}
+// artifactDigests returns the digests of the downloaded artifact. They are computed from
+// the download itself so they are reported even if the archive is removed after unpacking
+func artifactDigests(body []byte, includeSHA512 bool) []initializr.MetadataPair {
+	pairs := []initializr.MetadataPair{{Name: "sha256", Value: fmt.Sprintf("%x", sha256.Sum256(body))}}
+	if includeSHA512 {
+		pairs = append(pairs, initializr.MetadataPair{Name: "sha512", Value: fmt.Sprintf("%x", sha512.Sum512(body))})
+	}
+
+	return pairs
+}
+
+// unpack extracts the downloaded archive into the destination, or the unpack_dir under it
+func unpack(destinationDir, fileName string, params Params) error {
+	unpackDir := filepath.Join(destinationDir, params.UnpackDir)
+	if rel, err := filepath.Rel(destinationDir, unpackDir); err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return fmt.Errorf("unpack_dir %s must be inside the destination directory", params.UnpackDir)
+	}
 
-	targetURL := request.Source.URL
-	targetURL.Path = "/dependencies"
+	if params.StripComponents < 0 {
+		return fmt.Errorf("strip_components must not be negative, got %d", params.StripComponents)
+	}
 
-	params := url.Values{}
-	params.Add("bootVersion", request.Version.ID)
-	targetURL.RawQuery = params.Encode()
+	archivePath := filepath.Join(destinationDir, fileName)
+	if _, err := initializr.Unpack(archivePath, unpackDir, params.StripComponents); err != nil {
+		return fmt.Errorf("unpacking %s: %s", fileName, err.Error())
+	}
 
-	req, err := http.NewRequest("GET", targetURL.String(), nil)
+	if params.KeepArchive {
+		return nil
+	}
+
+	return os.Remove(archivePath)
+}
+
+// paramNames maps Initializr parameter names to the names used in Params where they differ
+var paramNames = map[string]string{
+	"javaVersion": "jdk_version",
+}
+
+// validateParams checks the params against the live metadata so that every mistake is
+// reported at once, by the name it was given in the pipeline
+func validateParams(client *initializr.Client, projectType string, queryParams map[string]string) error {
+	metadata, err := client.Metadata()
 	if err != nil {
 		return err
 	}
 
-	req.Header.Add("Accept", initializr.AcceptHeader)
+	params := map[string]string{"type": projectType}
+	for key, val := range queryParams {
+		params[key] = val
+	}
+
+	err = metadata.ValidateParams(params)
+	if invalid, ok := err.(initializr.ValidationError); ok {
+		for i := range invalid {
+			if name, ok := paramNames[invalid[i].Name]; ok {
+				invalid[i].Name = name
+			}
+		}
+	}
 
-	httpResponse, err := command.Client.Do(req)
+	return err
+}
+
+// writeDependencies writes available-dependencies, every dependency that can be used with
+// the Boot version along with its coordinates and the group it is listed under, and the BOMs
+// and repositories those dependencies need to boms.json and repositories.json
+func (command *Command) writeDependencies(client *initializr.Client, destDir string, request Request) error {
+	bootVersion, err := initializr.ParseBootVersion(request.Version.ID)
 	if err != nil {
 		return err
 	}
 
-	bytes, err := ioutil.ReadAll(httpResponse.Body)
+	metadata, err := client.Metadata()
 	if err != nil {
 		return err
 	}
 
-	if httpResponse.StatusCode != 200 {
-		return fmt.Errorf("Expected 200, got %d with response %s", httpResponse.StatusCode, string(bytes))
+	catalog, err := client.DependencyCatalog(bootVersion)
+	if err != nil {
+		return err
 	}
 
-	err = json.Unmarshal(bytes, &depResponse)
-	if err != nil {
+	entries := initializr.BuildCatalog(metadata, catalog)
+	if err = writeJSON(filepath.Join(destDir, "available-dependencies"), entries); err != nil {
 		return err
 	}
 
-	deps := make([]string, len(depResponse.Dependencies)+len(depResponse.BOMs))
-	curIdx := 0
-	for key := range depResponse.Dependencies {
-		deps[curIdx] = key
-		curIdx++
+	if err = writeJSON(filepath.Join(destDir, "boms.json"), catalog.BOMList()); err != nil {
+		return err
 	}
 
-	for key := range depResponse.BOMs {
-		deps[curIdx] = key
-		curIdx++
+	if err = writeJSON(filepath.Join(destDir, "repositories.json"), catalog.RepositoryList()); err != nil {
+		return err
 	}
 
-	encoded, err := json.Marshal(deps)
+	if !request.Params.DependenciesYAML {
+		return nil
+	}
+
+	encoded, err := yaml.Marshal(entries)
 	if err != nil {
 		return err
 	}
 
-	return ioutil.WriteFile(filepath.Join(destDir, "available-dependencies"), encoded, 0644)
+	return ioutil.WriteFile(filepath.Join(destDir, "available-dependencies.yml"), encoded, 0644)
 }
 
-func empty(s string) bool {
-	return strings.TrimSpace(s) == ""
+// writeProject parses a generated Maven build and writes it to project.json. It returns nil
+// for Gradle builds and for artifacts that are not builds at all, such as an archive of a
+// custom project type without a build file
+func writeProject(destDir, artifactPath string) (*initializr.POM, error) {
+	fileName := filepath.Base(artifactPath)
+	if !isArchive(fileName) && fileName != "pom.xml" {
+		return nil, nil
+	}
+
+	buildFile, contents, err := initializr.ReadBuildFile(artifactPath)
+	if _, ok := err.(initializr.NoBuildFileError); ok {
+		return nil, nil
+	}
+
+	if err != nil || buildFile != "pom.xml" {
+		return nil, err
+	}
+
+	pom, err := initializr.ParsePOM(contents)
+	if err != nil {
+		return nil, fmt.Errorf("parsing the generated pom.xml: %s", err.Error())
+	}
+
+	return pom, writeJSON(filepath.Join(destDir, "project.json"), pom)
 }
 
-func setValueOrDefault(values *url.Values, key, paramValue, defaultValue string) {
-	if empty(paramValue) {
-		values.Add(key, defaultValue)
-	} else {
-		values.Add(key, paramValue)
+// writeOfflineManifest writes offline-manifest.json for the generated build file
+func (command *Command) writeOfflineManifest(client *initializr.Client, destDir string, request Request, artifactPath string) error {
+	bootVersion, err := initializr.ParseBootVersion(request.Version.ID)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := client.DependencyCatalog(bootVersion)
+	if err != nil {
+		return err
 	}
+
+	buildFile, contents, err := initializr.ReadBuildFile(artifactPath)
+	if err != nil {
+		return err
+	}
+
+	var dependencyIDs []string
+	for _, id := range strings.Split(request.Params.Dependencies, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			dependencyIDs = append(dependencyIDs, id)
+		}
+	}
+
+	manifest, err := initializr.BuildOfflineManifest(catalog, dependencyIDs, buildFile, contents)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(filepath.Join(destDir, "offline-manifest.json"), manifest)
+}
+
+func writeJSON(p string, v interface{}) error {
+	encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(p, encoded, 0644)
+}
+
+func isArchive(fileName string) bool {
+	return strings.HasSuffix(fileName, ".zip") || strings.HasSuffix(fileName, ".tgz")
+}
+
+func empty(s string) bool {
+	return strings.TrimSpace(s) == ""
 }
 
-func setValue(values *url.Values, key, paramValue string) {
+func setValue(values map[string]string, key, paramValue string) {
 	if !empty(paramValue) {
-		values.Add(key, paramValue)
+		values[key] = paramValue
 	}
 }
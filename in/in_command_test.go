@@ -1,8 +1,15 @@
 package in_test
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http/httptest"
 	"net/url"
@@ -87,10 +94,15 @@ func TestInCommand(t *testing.T) {
 				Expect(filepath.Join(destDir, "version")).To(BeARegularFile())
 				Expect(filepath.Join(destDir, "url")).To(BeARegularFile())
 				Expect(filepath.Join(destDir, "available-dependencies")).To(BeARegularFile())
+				Expect(filepath.Join(destDir, "boms.json")).To(BeARegularFile())
+				Expect(filepath.Join(destDir, "repositories.json")).To(BeARegularFile())
+				Expect(filepath.Join(destDir, "project.json")).To(BeARegularFile())
+				Expect(filepath.Join(destDir, "checksums")).To(BeARegularFile())
+				Expect(filepath.Join(destDir, "manifest.json")).To(BeARegularFile())
 
 				fileList, err := ioutil.ReadDir(destDir)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(fileList).To(HaveLen(4))
+				Expect(fileList).To(HaveLen(9))
 			})
 
 			it("Should generate all the appropriate metadata", func() {
@@ -104,6 +116,23 @@ func TestInCommand(t *testing.T) {
 				Expect(resp.Metadata[1].Value).To(Equal("2.0.2.RELEASE"))
 			})
 
+			it("Should generate a version emitted by track: dependencies for its Boot version", func() {
+				request.Version = initializr.Version{Name: "2.0.2.RELEASE", ID: "sha256:0123456789abcdef"}
+
+				resp, err := command.Run(destDir, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Version).To(Equal(request.Version))
+				Expect(resp.Metadata[1].Value).To(Equal("2.0.2.RELEASE"))
+
+				contents, err := ioutil.ReadFile(filepath.Join(destDir, "version"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("2.0.2.RELEASE"))
+
+				contents, err = ioutil.ReadFile(filepath.Join(destDir, "url"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(ContainSubstring("bootVersion=2.0.2.RELEASE"))
+			})
+
 			it("Should not corrupt the downloaded files", func() {
 				_, err := command.Run(destDir, request)
 				Expect(err).NotTo(HaveOccurred())
@@ -123,14 +152,911 @@ func TestInCommand(t *testing.T) {
 				err = xml.NewDecoder(pomFile).Decode(new(interface{}))
 				Expect(err).NotTo(HaveOccurred())
 
-				depBody := make([]string, 0, 116)
+				depBody := make([]initializr.CatalogEntry, 0, 108)
 				err = json.Unmarshal(depBytes, &depBody)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(depBody).To(HaveLen(116))
+				Expect(depBody).To(HaveLen(108))
 
-				Expect(string(urlBytes)).To(Equal(initializrServer.URL + "/pom.xml?bootVersion=2.0.2.RELEASE&type=maven-build"))
+				Expect(string(urlBytes)).To(Equal(initializrServer.URL + "/pom.xml?type=maven-build&bootVersion=2.0.2.RELEASE"))
 				Expect(string(versionBytes)).To(Equal("2.0.2.RELEASE"))
 			})
+
+			it("Should describe each available dependency", func() {
+				_, err := command.Run(destDir, request)
+				Expect(err).NotTo(HaveOccurred())
+
+				depBytes, err := ioutil.ReadFile(filepath.Join(destDir, "available-dependencies"))
+				Expect(err).NotTo(HaveOccurred())
+
+				var deps []initializr.CatalogEntry
+				Expect(json.Unmarshal(depBytes, &deps)).To(Succeed())
+				Expect(deps[0]).To(Equal(initializr.CatalogEntry{
+					ID:         "azure-active-directory",
+					Name:       "Azure Active Directory",
+					Group:      "Azure",
+					GroupID:    "com.microsoft.azure",
+					ArtifactID: "azure-active-directory-spring-boot-starter",
+					Scope:      "compile",
+					BOM:        "azure",
+				}))
+
+				Expect(filepath.Join(destDir, "available-dependencies.yml")).NotTo(BeAnExistingFile())
+			})
+
+			it("Should write the BOMs and repositories separately", func() {
+				_, err := command.Run(destDir, request)
+				Expect(err).NotTo(HaveOccurred())
+
+				bomBytes, err := ioutil.ReadFile(filepath.Join(destDir, "boms.json"))
+				Expect(err).NotTo(HaveOccurred())
+
+				var boms []initializr.BOMEntry
+				Expect(json.Unmarshal(bomBytes, &boms)).To(Succeed())
+				Expect(boms).To(HaveLen(8))
+				Expect(boms).To(ContainElement(initializr.BOMEntry{
+					ID:           "spring-cloud",
+					GroupID:      "org.springframework.cloud",
+					ArtifactID:   "spring-cloud-dependencies",
+					Version:      "Finchley.RC2",
+					Repositories: []string{"spring-milestones"},
+				}))
+
+				repoBytes, err := ioutil.ReadFile(filepath.Join(destDir, "repositories.json"))
+				Expect(err).NotTo(HaveOccurred())
+
+				var repos []initializr.RepositoryEntry
+				Expect(json.Unmarshal(repoBytes, &repos)).To(Succeed())
+				Expect(repos).To(Equal([]initializr.RepositoryEntry{
+					{ID: "spring-milestones", Name: "Spring Milestones", URL: "https://repo.spring.io/milestone"},
+				}))
+			})
+
+			it("Should write the dependencies as YAML when asked", func() {
+				request.Params.DependenciesYAML = true
+
+				_, err := command.Run(destDir, request)
+				Expect(err).NotTo(HaveOccurred())
+
+				ymlBytes, err := ioutil.ReadFile(filepath.Join(destDir, "available-dependencies.yml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(ymlBytes)).To(HavePrefix("- id: azure-active-directory\n  name: Azure Active Directory\n  group: Azure\n"))
+			})
+
+			when("Describing the generated POM", func() {
+				it("Should write project.json and show the parent and Java versions", func() {
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata).To(ContainElement(initializr.MetadataPair{Name: "parent_version", Value: "2.0.2.RELEASE"}))
+					Expect(resp.Metadata).To(ContainElement(initializr.MetadataPair{Name: "java_version", Value: "1.8"}))
+
+					body, err := ioutil.ReadFile(filepath.Join(destDir, "project.json"))
+					Expect(err).NotTo(HaveOccurred())
+
+					var pom initializr.POM
+					Expect(json.Unmarshal(body, &pom)).To(Succeed())
+					Expect(pom.Parent).To(Equal(&initializr.MavenParent{
+						GroupID:    "org.springframework.boot",
+						ArtifactID: "spring-boot-starter-parent",
+						Version:    "2.0.2.RELEASE",
+					}))
+					Expect(pom.Properties).To(HaveKeyWithValue("project.build.sourceEncoding", "UTF-8"))
+					Expect(pom.Dependencies).To(ContainElement(initializr.MavenDependency{
+						GroupID:    "org.springframework.boot",
+						ArtifactID: "spring-boot-starter-test",
+						Scope:      "test",
+					}))
+					Expect(pom.Plugins).To(Equal([]initializr.MavenPlugin{
+						{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-maven-plugin"},
+					}))
+				})
+
+				it("Should read the POM inside a project archive", func() {
+					request.Params.Type = "maven-project"
+					request.Params.Unpack = true
+
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata).To(ContainElement(initializr.MetadataPair{Name: "parent_version", Value: "2.0.2.RELEASE"}))
+					Expect(filepath.Join(destDir, "project.json")).To(BeARegularFile())
+				})
+
+				it("Should skip Gradle builds", func() {
+					request.Params.Type = "gradle-build"
+
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata).To(HaveLen(3))
+					Expect(filepath.Join(destDir, "project.json")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			when("Recording checksums", func() {
+				var pomSHA256 string
+
+				it.Before(func() {
+					pom, err := ioutil.ReadFile("testdata/pom.xml")
+					Expect(err).NotTo(HaveOccurred())
+
+					pomSHA256 = fmt.Sprintf("%x", sha256.Sum256(pom))
+				})
+
+				it("Should describe every file in the manifest", func() {
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata).To(ContainElement(initializr.MetadataPair{Name: "sha256", Value: pomSHA256}))
+
+					manifestBytes, err := ioutil.ReadFile(filepath.Join(destDir, "manifest.json"))
+					Expect(err).NotTo(HaveOccurred())
+
+					var manifest initializr.Manifest
+					Expect(json.Unmarshal(manifestBytes, &manifest)).To(Succeed())
+					Expect(manifest.Artifact).To(Equal("pom.xml"))
+					Expect(manifest.Files).To(HaveLen(7))
+
+					entry, ok := manifest.Entry("pom.xml")
+					Expect(ok).To(BeTrue())
+					Expect(entry.SHA256).To(Equal(pomSHA256))
+					Expect(entry.SHA512).To(BeEmpty())
+
+					checksums, err := ioutil.ReadFile(filepath.Join(destDir, "checksums"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(checksums)).To(ContainSubstring("SHA256 (pom.xml) = " + pomSHA256 + "\n"))
+				})
+
+				it("Should add SHA-512 digests when asked", func() {
+					request.Params.SHA512 = true
+
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata[len(resp.Metadata)-1].Name).To(Equal("sha512"))
+
+					checksums, err := ioutil.ReadFile(filepath.Join(destDir, "checksums"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(checksums)).To(ContainSubstring("SHA512 (pom.xml) = "))
+				})
+
+				it("Should still report the digest of an archive that was unpacked and removed", func() {
+					request.Params.Type = "maven-project"
+					request.Params.Unpack = true
+
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					zipBytes, err := ioutil.ReadFile("testdata/starter.zip")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata).To(ContainElement(initializr.MetadataPair{Name: "sha256", Value: fmt.Sprintf("%x", sha256.Sum256(zipBytes))}))
+
+					manifestBytes, err := ioutil.ReadFile(filepath.Join(destDir, "manifest.json"))
+					Expect(err).NotTo(HaveOccurred())
+
+					var manifest initializr.Manifest
+					Expect(json.Unmarshal(manifestBytes, &manifest)).To(Succeed())
+					Expect(manifest.Artifact).To(BeEmpty())
+
+					_, ok := manifest.Entry("demo/pom.xml")
+					Expect(ok).To(BeTrue())
+				})
+			})
+
+			it("Should use the endpoint the metadata advertises for a custom type", func() {
+				request.Params.Type = "gradle-kotlin-project"
+				request.Params.Dependencies = "web,actuator"
+
+				resp, err := command.Run(destDir, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Metadata[0].Value).To(Equal("starter.zip"))
+				Expect(filepath.Join(destDir, "starter.zip")).To(BeARegularFile())
+
+				urlBytes, err := ioutil.ReadFile(filepath.Join(destDir, "url"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(urlBytes)).To(Equal(initializrServer.URL + "/starter.zip?type=gradle-kotlin-project&dependencies=web%2Cactuator&bootVersion=2.0.2.RELEASE"))
+			})
+
+			it("Should generate a custom type whose archive has no build file", func() {
+				request.Params.Type = "bazel-project"
+
+				resp, err := command.Run(destDir, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Metadata[0].Value).To(Equal("bazel.zip"))
+				Expect(filepath.Join(destDir, "bazel.zip")).To(BeARegularFile())
+				Expect(filepath.Join(destDir, "project.json")).NotTo(BeAnExistingFile())
+			})
+
+			it("Should list the valid types when the type is unknown", func() {
+				request.Params.Type = "ant-project"
+
+				_, err := command.Run(destDir, request)
+				Expect(err).To(MatchError(ContainSubstring(`type: "ant-project" is not supported; allowed values are maven-project, maven-build, gradle-project, gradle-build, gradle-kotlin-project, bazel-project`)))
+			})
+
+			it("Should report every invalid param before generating anything", func() {
+				request.Params.Packaging = "jarr"
+				request.Params.JDKVersion = "11"
+				request.Params.Dependencies = "web,actuatr,mobile"
+
+				_, err := command.Run(destDir, request)
+				Expect(err).To(BeAssignableToTypeOf(initializr.ValidationError{}))
+				Expect(err.Error()).To(Equal(`invalid project parameters:
+  packaging: "jarr" is not supported (did you mean "jar"?); allowed values are jar, war
+  jdk_version: "11" is not supported (did you mean "10"?); allowed values are 10, 1.8
+  dependencies: "actuatr" is not a known dependency (did you mean "actuator"?)
+  dependencies: "mobile" requires Spring Boot [1.0.0.RELEASE, 2.0.0.M1), not 2.0.2.RELEASE`))
+
+				Expect(filepath.Join(destDir, "pom.xml")).NotTo(BeAnExistingFile())
+			})
+
+			when("Choosing the archive format", func() {
+				it.Before(func() {
+					request.Params.Type = "gradle-project"
+					request.Params.ArchiveFormat = "tgz"
+				})
+
+				it("Should download starter.tgz", func() {
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata[0]).To(Equal(initializr.MetadataPair{Name: "file", Value: "starter.tgz"}))
+
+					Expect(filepath.Join(destDir, "starter.tgz")).To(BeARegularFile())
+					Expect(filepath.Join(destDir, "starter.zip")).NotTo(BeAnExistingFile())
+
+					urlBytes, err := ioutil.ReadFile(filepath.Join(destDir, "url"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(urlBytes)).To(Equal(initializrServer.URL + "/starter.tgz?type=gradle-project&bootVersion=2.0.2.RELEASE"))
+				})
+
+				it("Should unpack starter.tgz", func() {
+					request.Params.Unpack = true
+					request.Params.StripComponents = 1
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(filepath.Join(destDir, "pom.xml")).To(BeARegularFile())
+					Expect(filepath.Join(destDir, "starter.tgz")).NotTo(BeAnExistingFile())
+				})
+
+				it("Should reject a format for a build file", func() {
+					request.Params.Type = "maven-build"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError("/pom.xml does not generate a project archive, so archive format tgz cannot be used"))
+				})
+
+				it("Should reject an unknown format", func() {
+					request.Params.ArchiveFormat = "rar"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError(`archive format must be zip or tgz, got "rar"`))
+				})
+			})
+
+			when("Normalizing the project archive", func() {
+				it.Before(func() {
+					request.Params.Type = "maven-project"
+					request.Params.Normalize = true
+				})
+
+				it("Should produce the same bytes for the same inputs", func() {
+					first, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					second, err := command.Run(destDir+"-again", request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(second.Metadata).To(Equal(first.Metadata))
+
+					zipBytes, err := ioutil.ReadFile("testdata/starter.zip")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(first.Metadata).NotTo(ContainElement(initializr.MetadataPair{Name: "sha256", Value: fmt.Sprintf("%x", sha256.Sum256(zipBytes))}))
+				})
+
+				it("Should reset every modification time", func() {
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					reader, err := zip.OpenReader(filepath.Join(destDir, "starter.zip"))
+					Expect(err).NotTo(HaveOccurred())
+					defer reader.Close()
+
+					Expect(reader.File).NotTo(BeEmpty())
+					for _, f := range reader.File {
+						Expect(f.Modified.Equal(initializr.NormalizedModTime)).To(BeTrue(), f.Name)
+					}
+				})
+
+				it("Should normalize starter.tgz the same way every time", func() {
+					request.Params.ArchiveFormat = "tgz"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = command.Run(destDir+"-again", request)
+					Expect(err).NotTo(HaveOccurred())
+
+					first, err := ioutil.ReadFile(filepath.Join(destDir, "starter.tgz"))
+					Expect(err).NotTo(HaveOccurred())
+
+					second, err := ioutil.ReadFile(filepath.Join(destDir+"-again", "starter.tgz"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(second).To(Equal(first))
+
+					gz, err := gzip.NewReader(bytes.NewReader(first))
+					Expect(err).NotTo(HaveOccurred())
+
+					modes := make(map[string]int64)
+					reader := tar.NewReader(gz)
+					for {
+						header, err := reader.Next()
+						if err == io.EOF {
+							break
+						}
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(header.ModTime.Equal(initializr.NormalizedModTime)).To(BeTrue(), header.Name)
+						modes[header.Name] = header.Mode
+					}
+
+					Expect(modes).To(Equal(map[string]int64{
+						"demo/":        0755,
+						"demo/mvnw":    0755,
+						"demo/pom.xml": 0644,
+						"demo/src/main/java/com/example/demo/DemoApplication.java": 0644,
+					}))
+				})
+
+				it("Should leave build files alone", func() {
+					request.Params.Type = "maven-build"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					pom, err := ioutil.ReadFile(filepath.Join(destDir, "pom.xml"))
+					Expect(err).NotTo(HaveOccurred())
+
+					expected, err := ioutil.ReadFile("testdata/pom.xml")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(pom).To(Equal(expected))
+				})
+			})
+
+			when("Generating several variants", func() {
+				it.Before(func() {
+					request.Params.Dependencies = "web"
+					request.Params.Variants = []in.Variant{
+						{"variant": "maven", "type": "maven-build"},
+						{"variant": "gradle", "type": "gradle-build", "jdk_version": "10"},
+					}
+				})
+
+				it("Should generate each variant into its own directory", func() {
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata).To(Equal([]initializr.MetadataPair{
+						{Name: "maven", Value: "maven/pom.xml"},
+						{Name: "gradle", Value: "gradle/build.gradle"},
+						{Name: "version", Value: "2.0.2.RELEASE"},
+					}))
+
+					Expect(filepath.Join(destDir, "maven", "pom.xml")).To(BeARegularFile())
+					Expect(filepath.Join(destDir, "maven", "manifest.json")).To(BeARegularFile())
+					Expect(filepath.Join(destDir, "gradle", "build.gradle")).To(BeARegularFile())
+					Expect(filepath.Join(destDir, "version")).To(BeARegularFile())
+
+					urlBytes, err := ioutil.ReadFile(filepath.Join(destDir, "gradle", "url"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(urlBytes)).To(Equal(initializrServer.URL + "/build.gradle?type=gradle-build&dependencies=web&javaVersion=10&bootVersion=2.0.2.RELEASE"))
+				})
+
+				it("Should report every variant that failed", func() {
+					request.Params.Parallelism = 1
+					request.Params.Variants = append(request.Params.Variants,
+						in.Variant{"variant": "war", "type": "maven-build", "packaging": "warr"},
+						in.Variant{"variant": "ant", "type": "ant-project"},
+					)
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(HavePrefix("2 of 4 variants failed:\nwar: invalid project parameters:\n    packaging: \"warr\""))
+					Expect(err.Error()).To(ContainSubstring("\nant: invalid project parameters:\n    type: \"ant-project\" is not supported"))
+				})
+
+				it("Should resolve a relative project next to the destination", func() {
+					project, err := filepath.Abs("testdata/projects/maven-service")
+					Expect(err).NotTo(HaveOccurred())
+
+					request.Params.Project, err = filepath.Rel(filepath.Dir(destDir), project)
+					Expect(err).NotTo(HaveOccurred())
+					request.Params.Variants = request.Params.Variants[:1]
+
+					_, err = command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(filepath.Join(destDir, "maven", "drift.json")).To(BeARegularFile())
+				})
+
+				it("Should resolve a relative project next to a relative destination", func() {
+					project, err := filepath.Abs("testdata/projects/maven-service")
+					Expect(err).NotTo(HaveOccurred())
+
+					request.Params.Project, err = filepath.Rel(filepath.Dir(destDir), project)
+					Expect(err).NotTo(HaveOccurred())
+					request.Params.Variants = request.Params.Variants[:1]
+
+					wd, err := os.Getwd()
+					Expect(err).NotTo(HaveOccurred())
+					defer os.Chdir(wd)
+
+					Expect(os.Chdir(filepath.Dir(destDir))).To(Succeed())
+
+					_, err = command.Run(filepath.Base(destDir), request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(filepath.Join(destDir, "maven", "drift.json")).To(BeARegularFile())
+				})
+
+				it("Should let each variant set the project name", func() {
+					request.Params.Name = "shared"
+					request.Params.Variants[0]["name"] = "maven-service"
+					request.Params.Variants[1]["name"] = "gradle-service"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					for variant, name := range map[string]string{"maven": "maven-service", "gradle": "gradle-service"} {
+						urlBytes, err := ioutil.ReadFile(filepath.Join(destDir, variant, "url"))
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(urlBytes)).To(HaveSuffix("&name=" + name))
+					}
+				})
+
+				it("Should require a name for each variant", func() {
+					delete(request.Params.Variants[1], "variant")
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError("variant 2 needs a name under variant"))
+				})
+
+				it("Should reject params it does not know", func() {
+					request.Params.Variants[1]["jdk-version"] = "10"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError(`variant gradle: json: unknown field "jdk-version"`))
+				})
+
+				it("Should reject variants without a unique name", func() {
+					request.Params.Variants[1]["variant"] = "maven"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError("there is more than one variant named maven"))
+				})
+			})
+
+			when("Writing an offline manifest", func() {
+				var readManifest func() initializr.OfflineManifest
+
+				it.Before(func() {
+					request.Params.OfflineManifest = true
+					request.Params.Dependencies = "cloud-config-client"
+
+					readManifest = func() initializr.OfflineManifest {
+						body, err := ioutil.ReadFile(filepath.Join(destDir, "offline-manifest.json"))
+						Expect(err).NotTo(HaveOccurred())
+
+						var manifest initializr.OfflineManifest
+						Expect(json.Unmarshal(body, &manifest)).To(Succeed())
+						return manifest
+					}
+				})
+
+				it("Should list everything a Maven build needs", func() {
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					manifest := readManifest()
+					Expect(manifest.BootVersion).To(Equal("2.0.2.RELEASE"))
+					Expect(manifest.Artifacts).To(Equal([]initializr.OfflineArtifact{
+						{Kind: "bom", GroupID: "org.springframework.boot", ArtifactID: "spring-boot-dependencies", Version: "2.0.2.RELEASE"},
+						{Kind: "bom", GroupID: "org.springframework.cloud", ArtifactID: "spring-cloud-dependencies", Version: "Finchley.RC2"},
+						{Kind: "dependency", GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter"},
+						{Kind: "dependency", GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-test"},
+						{Kind: "dependency", GroupID: "org.springframework.cloud", ArtifactID: "spring-cloud-starter-config"},
+						{Kind: "parent", GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-parent", Version: "2.0.2.RELEASE"},
+						{Kind: "plugin", GroupID: "org.springframework.boot", ArtifactID: "spring-boot-maven-plugin"},
+					}))
+					Expect(manifest.Repositories).To(Equal([]initializr.RepositoryEntry{
+						{ID: "spring-milestones", Name: "Spring Milestones", URL: "https://repo.spring.io/milestone"},
+					}))
+				})
+
+				it("Should include the plugins on a Gradle buildscript classpath", func() {
+					request.Params.Type = "gradle-build"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(readManifest().Artifacts).To(ContainElement(initializr.OfflineArtifact{
+						Kind:       "plugin",
+						GroupID:    "org.springframework.boot",
+						ArtifactID: "spring-boot-gradle-plugin",
+						Version:    "2.0.2.RELEASE",
+					}))
+				})
+
+				it("Should read the build file from a project archive that is unpacked and removed", func() {
+					request.Params.Type = "maven-project"
+					request.Params.Unpack = true
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(filepath.Join(destDir, "starter.zip")).NotTo(BeAnExistingFile())
+					Expect(readManifest().Artifacts).To(ContainElement(initializr.OfflineArtifact{
+						Kind:       "parent",
+						GroupID:    "org.springframework.boot",
+						ArtifactID: "spring-boot-starter-parent",
+						Version:    "2.0.2.RELEASE",
+					}))
+				})
+			})
+
+			when("Comparing against another Boot version", func() {
+				it("Should compare against the previous version", func() {
+					request.Params.CompareTo = "previous"
+
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata).To(ContainElement(initializr.MetadataPair{Name: "compared_to", Value: "1.5.13.RELEASE"}))
+
+					diff, err := ioutil.ReadFile(filepath.Join(destDir, "compare.diff"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(diff)).To(HavePrefix("--- a/1.5.13.RELEASE/pom.xml\n+++ b/2.0.2.RELEASE/pom.xml\n"))
+					Expect(string(diff)).To(ContainSubstring("\n-\t\t<version>1.5.13.RELEASE</version>\n+\t\t<version>2.0.2.RELEASE</version>\n"))
+
+					body, err := ioutil.ReadFile(filepath.Join(destDir, "compare.json"))
+					Expect(err).NotTo(HaveOccurred())
+
+					var comparison initializr.ProjectComparison
+					Expect(json.Unmarshal(body, &comparison)).To(Succeed())
+					Expect(comparison).To(Equal(initializr.ProjectComparison{
+						FromBootVersion: "1.5.13.RELEASE",
+						ToBootVersion:   "2.0.2.RELEASE",
+						BuildFile:       "pom.xml",
+						Build: []initializr.CoordinateChange{
+							{Kind: "parent", ID: "org.springframework.boot:spring-boot-starter-parent", From: "1.5.13.RELEASE", To: "2.0.2.RELEASE"},
+						},
+						Catalog: []initializr.CoordinateChange{
+							{
+								Kind: "bom",
+								ID:   "spring-cloud",
+								From: "org.springframework.cloud:spring-cloud-dependencies:Edgware.SR3",
+								To:   "org.springframework.cloud:spring-cloud-dependencies:Finchley.RC2",
+							},
+						},
+					}))
+
+					Expect(filepath.Join(destDir, "manifest.json")).To(BeARegularFile())
+				})
+
+				it("Should compare the build file inside a project archive before unpacking it", func() {
+					request.Params.Type = "maven-project"
+					request.Params.Unpack = true
+					request.Params.CompareTo = "2.0.3.BUILD-SNAPSHOT"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					diff, err := ioutil.ReadFile(filepath.Join(destDir, "compare.diff"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(diff).To(BeEmpty())
+
+					body, err := ioutil.ReadFile(filepath.Join(destDir, "compare.json"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(body)).To(ContainSubstring(`"buildFile": "pom.xml"`))
+					Expect(string(body)).To(ContainSubstring(`"build": []`))
+				})
+
+				it("Should fail when there is no previous version", func() {
+					request.Version.ID = "1.5.13.RELEASE"
+					request.Params.CompareTo = "previous"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError("the Initializr does not list a Spring Boot version before 1.5.13.RELEASE to compare to"))
+				})
+			})
+
+			when("Detecting drift in an existing project", func() {
+				var mavenService, gradleService string
+
+				it.Before(func() {
+					var err error
+					mavenService, err = filepath.Abs("testdata/projects/maven-service")
+					Expect(err).NotTo(HaveOccurred())
+
+					gradleService, err = filepath.Abs("testdata/projects/gradle-service")
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("Should report a parent and Java version that differ", func() {
+					// relative to the directory the destination is in
+					project, err := filepath.Rel(filepath.Dir(destDir), mavenService)
+					Expect(err).NotTo(HaveOccurred())
+					request.Params.Project = project
+
+					resp, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.Metadata).To(ContainElement(initializr.MetadataPair{Name: "drift", Value: "2"}))
+
+					body, err := ioutil.ReadFile(filepath.Join(destDir, "drift.json"))
+					Expect(err).NotTo(HaveOccurred())
+
+					var report initializr.DriftReport
+					Expect(json.Unmarshal(body, &report)).To(Succeed())
+					Expect(report).To(Equal(initializr.DriftReport{
+						BootVersion: "2.0.2.RELEASE",
+						BuildFile:   "pom.xml",
+						Drift: []initializr.Drift{
+							{Kind: "parent", ID: "org.springframework.boot:spring-boot-starter-parent", Expected: "2.0.2.RELEASE", Actual: "2.0.1.RELEASE"},
+							{Kind: "java_version", ID: "java", Expected: "1.8", Actual: "10"},
+						},
+					}))
+
+					summary, err := ioutil.ReadFile(filepath.Join(destDir, "drift.txt"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(summary)).To(Equal("pom.xml has drifted from the Spring Boot 2.0.2.RELEASE skeleton in 2 places:\n" +
+						"  parent org.springframework.boot:spring-boot-starter-parent: 2.0.1.RELEASE, expected 2.0.2.RELEASE\n" +
+						"  java_version java: 10, expected 1.8\n"))
+				})
+
+				it("Should report a stale Boot plugin in a Gradle build", func() {
+					request.Params.Type = "gradle-build"
+					request.Params.Project = gradleService
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					body, err := ioutil.ReadFile(filepath.Join(destDir, "drift.json"))
+					Expect(err).NotTo(HaveOccurred())
+
+					var report initializr.DriftReport
+					Expect(json.Unmarshal(body, &report)).To(Succeed())
+					Expect(report.Drift).To(Equal([]initializr.Drift{
+						{Kind: "plugin", ID: "org.springframework.boot", Expected: "2.0.2.RELEASE", Actual: "2.0.0.RELEASE"},
+					}))
+				})
+
+				it("Should report the Java version of a Gradle toolchain", func() {
+					project, err := filepath.Abs("testdata/projects/toolchain-service")
+					Expect(err).NotTo(HaveOccurred())
+					request.Version.ID = "2.1.0.BUILD-SNAPSHOT"
+					request.Params.Type = "gradle-build"
+					request.Params.Project = project
+					request.Params.FailOnDrift = []string{"java_version"}
+
+					_, err = command.Run(destDir, request)
+					Expect(err).To(MatchError("1 differences from the generated build are not allowed by fail_on_drift:\n" +
+						"  java_version java: 11, expected 17"))
+
+					body, err := ioutil.ReadFile(filepath.Join(destDir, "drift.json"))
+					Expect(err).NotTo(HaveOccurred())
+
+					var report initializr.DriftReport
+					Expect(json.Unmarshal(body, &report)).To(Succeed())
+					Expect(report.Drift).To(Equal([]initializr.Drift{
+						{Kind: "java_version", ID: "java", Expected: "17", Actual: "11"},
+					}))
+				})
+
+				it("Should fail after writing the report when the drift is not allowed", func() {
+					request.Params.Project = mavenService
+					request.Params.FailOnDrift = []string{"parent"}
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError("1 differences from the generated build are not allowed by fail_on_drift:\n" +
+						"  parent org.springframework.boot:spring-boot-starter-parent: 2.0.1.RELEASE, expected 2.0.2.RELEASE"))
+					Expect(filepath.Join(destDir, "drift.json")).To(BeARegularFile())
+				})
+
+				it("Should succeed when the drift is allowed", func() {
+					request.Params.Project = mavenService
+					request.Params.FailOnDrift = []string{"bom", "plugin"}
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				it("Should reject an unknown kind of drift", func() {
+					request.Params.Project = mavenService
+					request.Params.FailOnDrift = []string{"parents"}
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError("fail_on_drift must only contain any, parent, plugin, bom, java_version, got parents"))
+				})
+
+				it("Should reject a project with a different build tool", func() {
+					request.Params.Type = "gradle-build"
+					request.Params.Project = mavenService
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError(fmt.Sprintf("project %s has a pom.xml, but the generated project has a build.gradle", mavenService)))
+				})
+			})
+
+			when("Writing a patch that upgrades an existing project", func() {
+				var readPatch func() string
+
+				it.Before(func() {
+					request.Params.UpgradePatch = true
+
+					readPatch = func() string {
+						patch, err := ioutil.ReadFile(filepath.Join(destDir, "upgrade.patch"))
+						Expect(err).NotTo(HaveOccurred())
+						return string(patch)
+					}
+				})
+
+				it("Should upgrade the parent of a POM and keep the rest of the project", func() {
+					project, err := filepath.Abs("testdata/projects/maven-service")
+					Expect(err).NotTo(HaveOccurred())
+					request.Params.Project = project
+
+					_, err = command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					// the project's coordinates, Java version and guava stay as they are
+					Expect(readPatch()).To(Equal(`--- a/pom.xml
++++ b/pom.xml
+@@ -14,7 +14,7 @@
+ 	<parent>
+ 		<groupId>org.springframework.boot</groupId>
+ 		<artifactId>spring-boot-starter-parent</artifactId>
+-		<version>2.0.1.RELEASE</version>
++		<version>2.0.2.RELEASE</version>
+ 		<relativePath/> <!-- lookup parent from repository -->
+ 	</parent>
+ 
+`))
+				})
+
+				it("Should upgrade the BOMs and Boot plugin of a POM without the Boot parent", func() {
+					project, err := filepath.Abs("testdata/projects/cloud-service")
+					Expect(err).NotTo(HaveOccurred())
+					request.Version.ID = "2.0.3.BUILD-SNAPSHOT"
+					request.Params.Project = project
+
+					_, err = command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					// a BOM version kept in a property is set there
+					Expect(readPatch()).To(Equal(`--- a/pom.xml
++++ b/pom.xml
+@@ -12,7 +12,7 @@
+ 
+ 	<properties>
+ 		<java.version>1.8</java.version>
+-		<spring-cloud.version>Finchley.RC1</spring-cloud.version>
++		<spring-cloud.version>Finchley.RC2</spring-cloud.version>
+ 	</properties>
+ 
+ 	<dependencies>
+@@ -33,7 +33,7 @@
+ 			<dependency>
+ 				<groupId>org.springframework.boot</groupId>
+ 				<artifactId>spring-boot-dependencies</artifactId>
+-				<version>2.0.1.RELEASE</version>
++				<version>2.0.3.BUILD-SNAPSHOT</version>
+ 				<type>pom</type>
+ 				<scope>import</scope>
+ 			</dependency>
+@@ -52,7 +52,7 @@
+ 			<plugin>
+ 				<groupId>org.springframework.boot</groupId>
+ 				<artifactId>spring-boot-maven-plugin</artifactId>
+-				<version>2.0.1.RELEASE</version>
++				<version>2.0.3.BUILD-SNAPSHOT</version>
+ 			</plugin>
+ 		</plugins>
+ 	</build>
+`))
+				})
+
+				it("Should upgrade the Boot version of a Gradle build and keep the rest of the project", func() {
+					project, err := filepath.Abs("testdata/projects/gradle-service")
+					Expect(err).NotTo(HaveOccurred())
+					request.Params.Type = "gradle-build"
+					request.Params.Project = project
+
+					_, err = command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(readPatch()).To(Equal(`--- a/build.gradle
++++ b/build.gradle
+@@ -1,6 +1,6 @@
+ buildscript {
+ 	ext {
+-		springBootVersion = '2.0.0.RELEASE'
++		springBootVersion = '2.0.2.RELEASE'
+ 	}
+ 	repositories {
+ 		mavenCentral()
+`))
+				})
+
+				it("Should write an empty patch for a project that matches the generated one", func() {
+					project, err := filepath.Abs("testdata")
+					Expect(err).NotTo(HaveOccurred())
+					request.Params.Project = project
+
+					_, err = command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(readPatch()).To(BeEmpty())
+				})
+
+				it("Should require a project", func() {
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError("upgrade_patch requires project"))
+				})
+			})
+
+			when("Unpacking the project archive", func() {
+				it.Before(func() {
+					request.Params.Type = "maven-project"
+					request.Params.Unpack = true
+				})
+
+				it("Should extract the project and remove the archive", func() {
+					request.Params.StripComponents = 1
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(filepath.Join(destDir, "pom.xml")).To(BeARegularFile())
+					Expect(filepath.Join(destDir, "src/main/java/com/example/demo/DemoApplication.java")).To(BeARegularFile())
+					Expect(filepath.Join(destDir, "starter.zip")).NotTo(BeAnExistingFile())
+
+					info, err := os.Stat(filepath.Join(destDir, "mvnw"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(info.Mode().Perm() & 0111).NotTo(BeZero())
+				})
+
+				it("Should extract into unpack_dir and keep the archive when asked", func() {
+					request.Params.UnpackDir = "project"
+					request.Params.KeepArchive = true
+
+					_, err := command.Run(destDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(filepath.Join(destDir, "project", "demo", "pom.xml")).To(BeARegularFile())
+					Expect(filepath.Join(destDir, "starter.zip")).To(BeARegularFile())
+				})
+
+				it("Should not extract outside the destination", func() {
+					request.Params.UnpackDir = "../elsewhere"
+
+					_, err := command.Run(destDir, request)
+					Expect(err).To(MatchError("unpack_dir ../elsewhere must be inside the destination directory"))
+				})
+
+				when("Checking the names of archive entries", func() {
+					for _, format := range []string{"zip", "tgz"} {
+						format := format
+
+						it("Should reject a "+format+" entry that climbs out with ..", func() {
+							_, err := initializr.Unpack("testdata/archives/parent."+format, filepath.Join(destDir, "unpacked"), 1)
+							Expect(err).To(MatchError(`archive entry "demo/../../evil" points outside of the destination directory`))
+							Expect(filepath.Join(destDir, "evil")).NotTo(BeAnExistingFile())
+						})
+
+						it("Should reject an absolute "+format+" entry", func() {
+							_, err := initializr.Unpack("testdata/archives/absolute."+format, filepath.Join(destDir, "unpacked"), 0)
+							Expect(err).To(MatchError(`archive entry "/tmp/evil" points outside of the destination directory`))
+							Expect(filepath.Join(destDir, "unpacked", "tmp", "evil")).NotTo(BeAnExistingFile())
+						})
+
+						it("Should clean harmless "+format+" entry names such as ./demo/pom.xml", func() {
+							written, err := initializr.Unpack("testdata/archives/dotted."+format, filepath.Join(destDir, "unpacked"), 1)
+							Expect(err).NotTo(HaveOccurred())
+							Expect(written).To(Equal([]string{"pom.xml", "mvnw", "src/main/resources/application.properties"}))
+						})
+					}
+				})
+			})
 		})
 	}, spec.Report(report.Terminal{}))
 }
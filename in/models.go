@@ -23,6 +23,54 @@ type Params struct {
 	Name         string `json:"name,omitempty"`
 	Description  string `json:"description,omitempty"`
 	PackageName  string `json:"package_name,omitempty"`
+
+	// ArchiveFormat is zip or tgz and selects the archive a project type is served as
+	ArchiveFormat string `json:"archive_format,omitempty"`
+	// Normalize rewrites a generated project archive so identical inputs give identical bytes
+	Normalize bool `json:"normalize,omitempty"`
+	// Unpack extracts a generated project archive after it is downloaded
+	Unpack bool `json:"unpack,omitempty"`
+	// UnpackDir is the subdirectory of the destination to extract into
+	UnpackDir string `json:"unpack_dir,omitempty"`
+	// StripComponents drops this many leading path elements from every archive entry
+	StripComponents int `json:"strip_components,omitempty"`
+	// KeepArchive keeps the archive in the destination after it is unpacked
+	KeepArchive bool `json:"keep_archive,omitempty"`
+	// SHA512 adds SHA-512 digests to the checksums, manifest and metadata
+	SHA512 bool `json:"sha512,omitempty"`
+	// DependenciesYAML also writes the available dependencies as available-dependencies.yml
+	DependenciesYAML bool `json:"dependencies_yaml,omitempty"`
+	// OfflineManifest writes offline-manifest.json, every artifact and repository the project
+	// needs, so that they can be mirrored for builds without internet access
+	OfflineManifest bool `json:"offline_manifest,omitempty"`
+	// Project is the path of an existing project to compare to the generated one. Relative
+	// paths are resolved against the directory the destination is in
+	Project string `json:"project,omitempty"`
+	// FailOnDrift are the kinds of drift from the generated build that fail the get, or any
+	FailOnDrift []string `json:"fail_on_drift,omitempty"`
+	// UpgradePatch writes upgrade.patch, which sets the Boot parent or plugins and the BOM
+	// versions of the project's build file to the generated ones
+	UpgradePatch bool `json:"upgrade_patch,omitempty"`
+	// CompareTo is a Boot version, or "previous", to generate the project for as well and
+	// compare the build file against
+	CompareTo string `json:"compare_to,omitempty"`
+
+	// Variants are generated into their own subdirectories instead of generating a single
+	// project. Each inherits these params and overrides the ones it sets
+	Variants []Variant `json:"variants,omitempty"`
+	// Parallelism limits how many variants are generated at once
+	Parallelism int `json:"parallelism,omitempty"`
+}
+
+// Variant is a named project to generate, written as the params it overrides plus its name
+// under variant, e.g. {"variant": "gradle-kotlin", "type": "gradle-project", "language": "kotlin"}.
+// It has a key of its own so that a variant can still set the name param
+type Variant map[string]interface{}
+
+// Name is the variant's name, which is also the subdirectory it is generated into
+func (v Variant) Name() string {
+	name, _ := v["variant"].(string)
+	return name
 }
 
 // Response is what is sent back to the container over Stdout
@@ -0,0 +1,151 @@
+package in
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jghiloni/spring-initializr-resource"
+)
+
+// defaultParallelism is how many variants are generated at once when parallelism is not set
+const defaultParallelism = 4
+
+// runVariants generates every variant into a subdirectory of destinationDir named after it
+func (command *Command) runVariants(client *initializr.Client, destinationDir string, request Request) (Response, error) {
+	requests, err := variantRequests(request)
+	if err != nil {
+		return emptyResponse, err
+	}
+
+	// a relative project is next to the destination, not next to each variant's directory
+	for i := range requests {
+		if !empty(requests[i].Params.Project) {
+			requests[i].Params.Project = projectDir(destinationDir, requests[i].Params.Project)
+		}
+	}
+
+	parallelism := request.Params.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+
+	variants := request.Params.Variants
+	responses := make([]Response, len(variants))
+	errs := make([]error, len(variants))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+	for i := range variants {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			responses[i], errs[i] = command.generate(client, filepath.Join(destinationDir, variants[i].Name()), requests[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	var failures []string
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", variants[i].Name(), strings.Replace(err.Error(), "\n", "\n  ", -1)))
+		}
+	}
+
+	if len(failures) > 0 {
+		return emptyResponse, fmt.Errorf("%d of %d variants failed:\n%s", len(failures), len(variants), strings.Join(failures, "\n"))
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(destinationDir, "version"), []byte(request.Version.ID), 0644); err != nil {
+		return emptyResponse, err
+	}
+
+	metadata := make([]initializr.MetadataPair, 0, len(variants)+1)
+	for i, variant := range variants {
+		// each variant reports the file it generated first
+		metadata = append(metadata, initializr.MetadataPair{
+			Name:  variant.Name(),
+			Value: filepath.Join(variant.Name(), responses[i].Metadata[0].Value),
+		})
+	}
+
+	metadata = append(metadata, initializr.MetadataPair{Name: "version", Value: request.Version.ID})
+	return Response{
+		Version:  request.Version,
+		Metadata: metadata,
+	}, nil
+}
+
+// variantRequests returns a request per variant, with the variant's params laid over the
+// shared ones
+func variantRequests(request Request) ([]Request, error) {
+	shared, err := json.Marshal(request.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	requests := make([]Request, 0, len(request.Params.Variants))
+	for i, variant := range request.Params.Variants {
+		name := variant.Name()
+		switch {
+		case strings.TrimSpace(name) == "":
+			return nil, fmt.Errorf("variant %d needs a name under variant", i+1)
+		case name == "." || name == ".." || strings.ContainsAny(name, `/\`):
+			return nil, fmt.Errorf("variant name %q must be usable as a directory name", name)
+		case seen[name]:
+			return nil, fmt.Errorf("there is more than one variant named %s", name)
+		}
+
+		seen[name] = true
+
+		merged := make(map[string]interface{})
+		if err = json.Unmarshal(shared, &merged); err != nil {
+			return nil, err
+		}
+
+		delete(merged, "variants")
+		delete(merged, "parallelism")
+		for key, val := range variant {
+			if key == "variant" {
+				continue
+			}
+
+			if key == "variants" || key == "parallelism" {
+				return nil, fmt.Errorf("variant %s: %s cannot be set on a variant", name, key)
+			}
+
+			merged[key] = val
+		}
+
+		body, err := json.Marshal(merged)
+		if err != nil {
+			return nil, err
+		}
+
+		// unknown keys are most likely typos, which would otherwise silently inherit
+		var params Params
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.DisallowUnknownFields()
+		if err = decoder.Decode(&params); err != nil {
+			return nil, fmt.Errorf("variant %s: %s", name, err.Error())
+		}
+
+		requests = append(requests, Request{
+			Source:  request.Source,
+			Version: request.Version,
+			Params:  params,
+		})
+	}
+
+	return requests, nil
+}
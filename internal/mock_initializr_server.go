@@ -1,13 +1,17 @@
 package internal
 
 import (
+	"bytes"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
 )
 
-// MockInitializrServer will create a server that mimics a Spring Initializr API
+// MockInitializrServer will create a server that mimics a Spring Initializr API. Links to
+// https://start.spring.io in the testdata are rewritten to point at the server itself. A
+// request with a bootVersion is served from the subdirectory named for it when the file exists
+// there, so that tests can generate different projects for different versions
 func MockInitializrServer(testdataDir string) *httptest.Server {
 	return httptest.NewTLSServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
 		fileName := request.URL.Path
@@ -15,14 +19,22 @@ func MockInitializrServer(testdataDir string) *httptest.Server {
 			fileName = "/initializr.json"
 		}
 
-		bytes, err := ioutil.ReadFile(filepath.Join(testdataDir, fileName))
+		body, err := ioutil.ReadFile(filepath.Join(testdataDir, request.URL.Query().Get("bootVersion"), fileName))
+		if err != nil {
+			body, err = ioutil.ReadFile(filepath.Join(testdataDir, fileName))
+		}
+
 		if err != nil {
 			response.WriteHeader(500)
 			response.Write([]byte(err.Error()))
 			return
 		}
 
+		if filepath.Ext(fileName) == ".json" {
+			body = bytes.Replace(body, []byte("https://start.spring.io"), []byte("https://"+request.Host), -1)
+		}
+
 		response.WriteHeader(200)
-		response.Write(bytes)
+		response.Write(body)
 	}))
 }
@@ -0,0 +1,135 @@
+package initializr
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File names written by WriteManifest
+const (
+	ChecksumsFile = "checksums"
+	ManifestFile  = "manifest.json"
+)
+
+// ManifestEntry describes one file in the destination directory
+type ManifestEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Mode   string `json:"mode"`
+	SHA256 string `json:"sha256"`
+	SHA512 string `json:"sha512,omitempty"`
+}
+
+// Manifest describes every file written to the destination directory, along with which
+// of them is the artifact generated by the Initializr
+type Manifest struct {
+	Artifact string          `json:"artifact,omitempty"`
+	Files    []ManifestEntry `json:"files"`
+}
+
+// Entry returns the manifest entry for the file at the given relative path
+func (m Manifest) Entry(p string) (ManifestEntry, bool) {
+	for _, entry := range m.Files {
+		if entry.Path == p {
+			return entry, true
+		}
+	}
+
+	return ManifestEntry{}, false
+}
+
+// WriteManifest hashes every file under dir and writes a checksums file, in the tagged
+// format understood by sha256sum -c, and a manifest.json. artifact is the path of the
+// generated file relative to dir, or empty if it is no longer there (e.g. after unpacking)
+func WriteManifest(dir, artifact string, includeSHA512 bool) (Manifest, error) {
+	manifest := Manifest{Artifact: filepath.ToSlash(artifact)}
+
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+		if rel == ChecksumsFile || rel == ManifestFile {
+			return nil
+		}
+
+		entry, err := hashFile(p, includeSHA512)
+		if err != nil {
+			return err
+		}
+
+		entry.Path = rel
+		entry.Size = info.Size()
+		entry.Mode = fmt.Sprintf("%04o", info.Mode().Perm())
+		manifest.Files = append(manifest.Files, entry)
+		return nil
+	})
+
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	if _, ok := manifest.Entry(manifest.Artifact); artifact != "" && !ok {
+		return Manifest{}, fmt.Errorf("artifact %s was not found in %s", artifact, dir)
+	}
+
+	var checksums strings.Builder
+	for _, entry := range manifest.Files {
+		fmt.Fprintf(&checksums, "SHA256 (%s) = %s\n", entry.Path, entry.SHA256)
+		if entry.SHA512 != "" {
+			fmt.Fprintf(&checksums, "SHA512 (%s) = %s\n", entry.Path, entry.SHA512)
+		}
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, ChecksumsFile), []byte(checksums.String()), 0644); err != nil {
+		return Manifest{}, err
+	}
+
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	return manifest, ioutil.WriteFile(filepath.Join(dir, ManifestFile), append(body, '\n'), 0644)
+}
+
+func hashFile(p string, includeSHA512 bool) (ManifestEntry, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+	defer f.Close()
+
+	sum256 := sha256.New()
+	var sum512 hash.Hash
+	writers := []io.Writer{sum256}
+	if includeSHA512 {
+		sum512 = sha512.New()
+		writers = append(writers, sum512)
+	}
+
+	if _, err = io.Copy(io.MultiWriter(writers...), f); err != nil {
+		return ManifestEntry{}, err
+	}
+
+	entry := ManifestEntry{SHA256: fmt.Sprintf("%x", sum256.Sum(nil))}
+	if sum512 != nil {
+		entry.SHA512 = fmt.Sprintf("%x", sum512.Sum(nil))
+	}
+
+	return entry, nil
+}
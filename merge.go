@@ -0,0 +1,261 @@
+package initializr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// bootBOM is the BOM that the Boot parent and the io.spring.dependency-management plugin import
+const bootBOM = "org.springframework.boot:spring-boot-dependencies"
+
+// MergeBuildFile returns the existing build file with the parts the Initializr owns set as
+// they are in the generated one: the Spring Boot parent or plugin, the dependency management
+// plugin and the versions of the BOMs both import. The project's coordinates, dependencies
+// and everything else are left as they are written in the existing build
+func MergeBuildFile(buildFile string, generated, existing []byte) ([]byte, error) {
+	switch buildFile {
+	case "pom.xml":
+		return mergePOM(generated, existing)
+	case "build.gradle", "build.gradle.kts":
+		return mergeGradleBuild(generated, existing)
+	default:
+		return nil, fmt.Errorf("%s is not a pom.xml or build.gradle(.kts)", buildFile)
+	}
+}
+
+// span is a range of a file
+type span struct {
+	start, end int
+}
+
+// edit replaces a span of a file with text
+type edit struct {
+	span
+	text string
+}
+
+func mergePOM(generated, existing []byte) ([]byte, error) {
+	generatedPOM, err := ParsePOM(generated)
+	if err != nil {
+		return nil, err
+	}
+
+	if generatedPOM.Parent == nil {
+		return nil, fmt.Errorf("the generated pom.xml has no parent")
+	}
+
+	existingPOM, err := ParsePOM(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := existing
+	bootVersion := generatedPOM.Parent.Version
+	if existingPOM.Parent != nil && existingPOM.Parent.ArtifactID == "spring-boot-starter-parent" {
+		if merged, err = UpgradePOM(merged, bootVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	// projects without the Boot parent import the Boot BOM instead
+	boms := map[string]string{bootBOM: bootVersion}
+	for _, bom := range generatedPOM.BOMImports() {
+		boms[bom.GroupID+":"+bom.ArtifactID] = bom.Version
+	}
+
+	managed, err := pomElements(merged, "project>dependencyManagement>dependencies>dependency")
+	if err != nil {
+		return nil, err
+	}
+
+	// a Boot plugin only has a version of its own in a project without the Boot parent
+	plugins, err := pomElements(merged, "project>build>plugins>plugin")
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []edit
+	setVersion := func(element span, version, want string) error {
+		if version == "" {
+			return nil
+		}
+
+		// a version kept in a property, such as ${spring-cloud.version}, is set there
+		if m := pomPropertyReference.FindStringSubmatch(version); m != nil && m[0] == version {
+			properties, err := pomElements(merged, "project>properties>"+m[1])
+			if err != nil || len(properties) == 0 {
+				return err
+			}
+
+			edits = append(edits, edit{properties[0], "<" + m[1] + ">" + want + "</" + m[1] + ">"})
+			return nil
+		}
+
+		text := pomVersionPattern.ReplaceAll(merged[element.start:element.end], []byte("${1}"+want+"${2}"))
+		edits = append(edits, edit{element, string(text)})
+		return nil
+	}
+
+	for _, element := range managed {
+		var dep MavenDependency
+		if err = xml.Unmarshal(merged[element.start:element.end], &dep); err != nil {
+			return nil, err
+		}
+
+		want, ok := boms[dep.GroupID+":"+dep.ArtifactID]
+		if !ok || dep.Scope != "import" || dep.Type != "pom" {
+			continue
+		}
+
+		if err = setVersion(element, dep.Version, want); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, element := range plugins {
+		var plugin MavenPlugin
+		if err = xml.Unmarshal(merged[element.start:element.end], &plugin); err != nil {
+			return nil, err
+		}
+
+		if plugin.GroupID != "org.springframework.boot" {
+			continue
+		}
+
+		if err = setVersion(element, plugin.Version, bootVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	return applyEdits(merged, edits), nil
+}
+
+// pomElements returns where each element at path, e.g. project>dependencies>dependency,
+// starts and ends
+func pomElements(contents []byte, path string) ([]span, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(contents))
+
+	var current []string
+	var elements []span
+	var start int
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return elements, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			current = append(current, t.Name.Local)
+			if strings.Join(current, ">") == path {
+				start = offset
+			}
+		case xml.EndElement:
+			if strings.Join(current, ">") == path {
+				elements = append(elements, span{start, int(decoder.InputOffset())})
+			}
+
+			current = current[:len(current)-1]
+		}
+	}
+}
+
+func mergeGradleBuild(generated, existing []byte) ([]byte, error) {
+	generatedBuild := ParseGradleBuild(generated)
+	boot, ok := generatedBuild.Plugin("org.springframework.boot")
+	if !ok || boot.Version == "" {
+		return nil, fmt.Errorf("the generated build does not declare a version for the org.springframework.boot plugin")
+	}
+
+	// builds generated before Spring Boot 2.1 apply the dependency management plugin without a version
+	dependencyManagement, _ := generatedBuild.Plugin(DependencyManagementPlugin)
+	merged, err := UpgradeGradleBuild(existing, boot.Version, dependencyManagement.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	boms := map[string]string{bootBOM: boot.Version}
+	for _, bom := range generatedBuild.BOMs {
+		boms[bom.GroupID+":"+bom.ArtifactID] = bom.Version
+	}
+
+	var edits []edit
+	for _, m := range gradleBOMPattern.FindAllSubmatchIndex(merged, -1) {
+		parts := strings.SplitN(string(merged[m[2]:m[3]]), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		want, ok := boms[parts[0]+":"+parts[1]]
+		if !ok {
+			continue
+		}
+
+		// a version kept in an ext property, such as ${springCloudVersion}, is set there
+		version := span{m[2] + len(parts[0]) + len(parts[1]) + 2, m[3]}
+		if ref := gradleReferencePattern.FindStringSubmatch(parts[2]); ref != nil && ref[0] == parts[2] {
+			if version, ok = gradleProperty(merged, ref[1]+ref[2]+ref[3]); !ok {
+				continue
+			}
+		}
+
+		edits = append(edits, edit{version, want})
+	}
+
+	return applyEdits(merged, edits), nil
+}
+
+// gradleProperty returns where the value of an ext property set in the build is
+func gradleProperty(contents []byte, name string) (span, bool) {
+	for _, m := range gradlePropertyPattern.FindAllSubmatchIndex(contents, -1) {
+		// only one of the Groovy and Kotlin forms matched, the other group is unset
+		var declared string
+		for _, group := range [][]int{m[2:4], m[4:6]} {
+			if group[0] >= 0 {
+				declared += string(contents[group[0]:group[1]])
+			}
+		}
+
+		if declared == name {
+			return span{m[6], m[7]}, true
+		}
+	}
+
+	for _, m := range gradleExtVersionPattern.FindAllSubmatchIndex(contents, -1) {
+		if string(contents[m[2]:m[3]]) == name {
+			return span{m[4], m[5]}, true
+		}
+	}
+
+	return span{}, false
+}
+
+// applyEdits makes edits to contents. Edits of the same span, as when two BOMs share a
+// version property, are made once
+func applyEdits(contents []byte, edits []edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+
+	edited := append([]byte(nil), contents...)
+	last := -1
+	for _, e := range edits {
+		if e.start == last {
+			continue
+		}
+
+		edited = append(edited[:e.start], append([]byte(e.text), edited[e.end:]...)...)
+		last = e.start
+	}
+
+	return edited
+}
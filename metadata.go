@@ -0,0 +1,232 @@
+package initializr
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Link is a HAL link as found under _links in the Initializr metadata
+type Link struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// Links maps a relation to its links. HAL allows either a single link or a list of links
+// per relation, so both are decoded into a list
+type Links map[string][]Link
+
+// UnmarshalJSON accepts both single links and lists of links for each relation
+func (l *Links) UnmarshalJSON(j []byte) error {
+	intermediate := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(j, &intermediate); err != nil {
+		return err
+	}
+
+	*l = make(Links, len(intermediate))
+	for rel, raw := range intermediate {
+		var single Link
+		if err := json.Unmarshal(raw, &single); err == nil {
+			(*l)[rel] = []Link{single}
+			continue
+		}
+
+		var list []Link
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return fmt.Errorf("_links.%s must be a link or a list of links: %s", rel, err.Error())
+		}
+
+		(*l)[rel] = list
+	}
+
+	return nil
+}
+
+// TextField is a free-form metadata field such as groupId or packageName
+type TextField struct {
+	Type    string `json:"type"`
+	Default string `json:"default,omitempty"`
+}
+
+// Option is one of the allowed values of a SelectField
+type Option struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Action      string            `json:"action,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// SelectField is a metadata field with a fixed list of values, such as packaging or bootVersion
+type SelectField struct {
+	Type    string   `json:"type"`
+	Default string   `json:"default,omitempty"`
+	Values  []Option `json:"values"`
+}
+
+// Option returns the option with the given ID
+func (f SelectField) Option(id string) (Option, bool) {
+	for _, option := range f.Values {
+		if option.ID == id {
+			return option, true
+		}
+	}
+
+	return Option{}, false
+}
+
+// IDs returns the IDs of every option, in the order the Initializr lists them
+func (f SelectField) IDs() []string {
+	ids := make([]string, 0, len(f.Values))
+	for _, option := range f.Values {
+		ids = append(ids, option.ID)
+	}
+
+	return ids
+}
+
+// Dependency is a starter that can be added to a generated project
+type Dependency struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	VersionRange string `json:"versionRange,omitempty"`
+	Links        Links  `json:"_links,omitempty"`
+}
+
+// Available reports whether the dependency can be used with the given Boot version
+func (d Dependency) Available(bootVersion BootVersion) (bool, error) {
+	r, err := ParseVersionRange(d.VersionRange)
+	if err != nil {
+		return false, fmt.Errorf("dependency %s: %s", d.ID, err.Error())
+	}
+
+	return r.Contains(bootVersion), nil
+}
+
+// DependencyGroup is a named group of dependencies such as "Web" or "SQL"
+type DependencyGroup struct {
+	Name   string       `json:"name"`
+	Values []Dependency `json:"values"`
+}
+
+// DependencyField is the hierarchical dependencies field of the metadata
+type DependencyField struct {
+	Type   string            `json:"type"`
+	Values []DependencyGroup `json:"values"`
+}
+
+// Metadata is the root document served by the Initializr
+type Metadata struct {
+	Links        Links           `json:"_links"`
+	Dependencies DependencyField `json:"dependencies"`
+	Type         SelectField     `json:"type"`
+	Packaging    SelectField     `json:"packaging"`
+	JavaVersion  SelectField     `json:"javaVersion"`
+	Language     SelectField     `json:"language"`
+	BootVersion  SelectField     `json:"bootVersion"`
+	GroupID      TextField       `json:"groupId"`
+	ArtifactID   TextField       `json:"artifactId"`
+	Version      TextField       `json:"version"`
+	Name         TextField       `json:"name"`
+	Description  TextField       `json:"description"`
+	PackageName  TextField       `json:"packageName"`
+}
+
+// Dependency returns the dependency with the given ID and the name of the group it belongs to
+func (m *Metadata) Dependency(id string) (Dependency, string, bool) {
+	for _, group := range m.Dependencies.Values {
+		for _, dep := range group.Values {
+			if dep.ID == id {
+				return dep, group.Name, true
+			}
+		}
+	}
+
+	return Dependency{}, "", false
+}
+
+// DependencyIDs returns the ID of every dependency, in the order the Initializr lists them
+func (m *Metadata) DependencyIDs() []string {
+	var ids []string
+	for _, group := range m.Dependencies.Values {
+		for _, dep := range group.Values {
+			ids = append(ids, dep.ID)
+		}
+	}
+
+	return ids
+}
+
+// BootVersions returns every Boot version the Initializr lists, in the order it lists them
+func (m *Metadata) BootVersions() (BootVersions, error) {
+	versions := make(BootVersions, 0, len(m.BootVersion.Values))
+	for _, option := range m.BootVersion.Values {
+		v, err := ParseBootVersion(option.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+// DefaultBootVersion returns the Boot version the Initializr selects by default
+func (m *Metadata) DefaultBootVersion() (BootVersion, error) {
+	if m.BootVersion.Default == "" {
+		return BootVersion{}, fmt.Errorf("no default Boot version is declared")
+	}
+
+	return ParseBootVersion(m.BootVersion.Default)
+}
+
+// Coordinates is a dependency entry of the /dependencies document
+type Coordinates struct {
+	GroupID    string `json:"groupId"`
+	ArtifactID string `json:"artifactId"`
+	Version    string `json:"version,omitempty"`
+	Scope      string `json:"scope,omitempty"`
+	BOM        string `json:"bom,omitempty"`
+	Repository string `json:"repository,omitempty"`
+}
+
+func (c Coordinates) String() string {
+	return gav(c.GroupID, c.ArtifactID, c.Version)
+}
+
+// BOM is a bill of materials entry of the /dependencies document
+type BOM struct {
+	GroupID      string   `json:"groupId"`
+	ArtifactID   string   `json:"artifactId"`
+	Version      string   `json:"version"`
+	Repositories []string `json:"repositories,omitempty"`
+}
+
+func (b BOM) String() string {
+	return gav(b.GroupID, b.ArtifactID, b.Version)
+}
+
+// Repository is a Maven repository entry of the /dependencies document
+type Repository struct {
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	SnapshotEnabled bool   `json:"snapshotEnabled"`
+}
+
+// DependencyCatalog is the /dependencies document for a single Boot version
+type DependencyCatalog struct {
+	BootVersion  string                 `json:"bootVersion"`
+	Dependencies map[string]Coordinates `json:"dependencies"`
+	Repositories map[string]Repository  `json:"repositories,omitempty"`
+	BOMs         map[string]BOM         `json:"boms,omitempty"`
+}
+
+func gav(groupID, artifactID, version string) string {
+	if version == "" {
+		return groupID + ":" + artifactID
+	}
+
+	return groupID + ":" + artifactID + ":" + version
+}
@@ -4,18 +4,46 @@ import (
 	"crypto/x509"
 	"net/url"
 	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// Track modes for the track source field
+const (
+	// TrackLatest emits every listed Boot version that passes the source filters
+	TrackLatest = "latest"
+	// TrackDefault emits a version only when the Initializr's default Boot version changes
+	TrackDefault = "default"
+	// TrackDependencies emits a version whenever the dependency catalog for the tracked Boot version changes
+	TrackDependencies = "dependencies"
+)
+
+// Policies for the on_delisted source field
+const (
+	// DelistedNewer emits every listed version newer than the delisted current version
+	DelistedNewer = "newer"
+	// DelistedLatest emits only the latest listed version
+	DelistedLatest = "latest"
+	// DelistedError fails the check
+	DelistedError = "error"
 )
 
 // Source is the data that is defined in the Concourse resource block
 type Source struct {
-	URL               *url.URL            `json:"url,omitempty"`
-	SkipTLSValidation bool                `json:"skip_tls_validation,omitempty"`
-	CACerts           []*x509.Certificate `json:"ca_certs,omitempty"`
-	ProductVersion    *regexp.Regexp      `json:"product_version,omitempty"`
-	IncludeSnapshots  bool                `json:"include_snapshots,omitempty"`
-	HTTPProxy         string              `json:"http_proxy,omitempty"`
-	HTTPSProxy        string              `json:"https_proxy,omitempty"`
-	NoProxy           string              `json:"no_proxy,omitempty"`
+	URL                  *url.URL            `json:"url,omitempty"`
+	SkipTLSValidation    bool                `json:"skip_tls_validation,omitempty"`
+	CACerts              []*x509.Certificate `json:"ca_certs,omitempty"`
+	ProductVersion       *regexp.Regexp      `json:"product_version,omitempty"`
+	IncludeSnapshots     bool                `json:"include_snapshots,omitempty"`
+	HTTPProxy            string              `json:"http_proxy,omitempty"`
+	HTTPSProxy           string              `json:"https_proxy,omitempty"`
+	NoProxy              string              `json:"no_proxy,omitempty"`
+	ReleaseChannels      []ReleaseType       `json:"release_channels,omitempty"`
+	VersionConstraint    semver.Range        `json:"-"`
+	Track                string              `json:"track,omitempty"`
+	RequiredDependencies []string            `json:"required_dependencies,omitempty"`
+	OnDelisted           string              `json:"on_delisted,omitempty"`
 }
 
 // Version is the data structure that is output by the check and in scripts
@@ -24,8 +52,48 @@ type Version struct {
 	ID   string `json:"id,omitempty"`
 }
 
+// BootVersion returns the Spring Boot version the Version stands for. Versions emitted with
+// track: dependencies have a catalog digest as their ID and the Boot version as their name
+func (v Version) BootVersion() string {
+	if strings.HasPrefix(v.ID, "sha256:") {
+		return v.Name
+	}
+
+	return v.ID
+}
+
 // MetadataPair is the datastructure that gets output with a Version from the in script
 type MetadataPair struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
 }
+
+// IncludesReleaseType reports whether check should emit versions of the given release type.
+// If release_channels is set, include_snapshots is ignored
+func (s Source) IncludesReleaseType(r ReleaseType) bool {
+	if len(s.ReleaseChannels) > 0 {
+		for _, channel := range s.ReleaseChannels {
+			if channel == r {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return r == GA || (s.IncludeSnapshots && r == Snapshot)
+}
+
+// IncludesVersion reports whether check should emit the given Boot version, taking
+// product_version, version_constraint and the accepted release types into account
+func (s Source) IncludesVersion(v BootVersion) bool {
+	if s.ProductVersion != nil && !s.ProductVersion.MatchString(v.ID) {
+		return false
+	}
+
+	if s.VersionConstraint != nil && !s.VersionConstraint(v.Semver()) {
+		return false
+	}
+
+	return s.IncludesReleaseType(v.ReleaseType)
+}
@@ -0,0 +1,212 @@
+package initializr
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NormalizedModTime is the modification time given to every entry of a normalized archive.
+// It is the earliest time a zip archive can represent
+var NormalizedModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type archiveEntry struct {
+	name string
+	dir  bool
+	mode os.FileMode
+	body []byte
+}
+
+// NormalizeArchive rewrites a zip or tar.gz archive in place so that the same contents always
+// produce the same bytes: entries are sorted by name, every modification time is
+// NormalizedModTime, owners are dropped, and permissions are 0755 for directories and
+// executables and 0644 for everything else
+func NormalizeArchive(archivePath string) error {
+	var entries []archiveEntry
+	var err error
+
+	switch {
+	case strings.HasSuffix(archivePath, ".zip"):
+		entries, err = readZipEntries(archivePath)
+	case strings.HasSuffix(archivePath, ".tgz"), strings.HasSuffix(archivePath, ".tar.gz"):
+		entries, err = readTarGzEntries(archivePath)
+	default:
+		err = fmt.Errorf("%s is not a zip or tar.gz archive", archivePath)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	var normalized bytes.Buffer
+	if strings.HasSuffix(archivePath, ".zip") {
+		err = writeZipEntries(&normalized, entries)
+	} else {
+		err = writeTarGzEntries(&normalized, entries)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	tmp := archivePath + ".normalized"
+	if err = ioutil.WriteFile(tmp, normalized.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, archivePath)
+}
+
+func normalizedMode(e archiveEntry) os.FileMode {
+	if e.dir || e.mode&0111 != 0 || executableNames[path.Base(e.name)] {
+		return 0755
+	}
+
+	return 0644
+}
+
+func readZipEntries(archivePath string) ([]archiveEntry, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	entries := make([]archiveEntry, 0, len(reader.File))
+	for _, f := range reader.File {
+		entry := archiveEntry{name: f.Name, dir: f.FileInfo().IsDir(), mode: f.Mode()}
+		if !entry.dir {
+			src, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+
+			entry.body, err = ioutil.ReadAll(src)
+			src.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func writeZipEntries(w io.Writer, entries []archiveEntry) error {
+	writer := zip.NewWriter(w)
+	for _, entry := range entries {
+		header := &zip.FileHeader{
+			Name:     entry.name,
+			Method:   zip.Deflate,
+			Modified: NormalizedModTime,
+		}
+
+		mode := normalizedMode(entry)
+		if entry.dir {
+			header.Method = zip.Store
+			mode |= os.ModeDir
+		}
+
+		header.SetMode(mode)
+		dst, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		if _, err = dst.Write(entry.body); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func readTarGzEntries(archivePath string) ([]archiveEntry, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	var entries []archiveEntry
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry := archiveEntry{name: header.Name, mode: header.FileInfo().Mode()}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			entry.dir = true
+		case tar.TypeReg:
+			if entry.body, err = ioutil.ReadAll(reader); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("archive entry %q is not a file or directory, which is not supported", header.Name)
+		}
+
+		entries = append(entries, entry)
+	}
+}
+
+func writeTarGzEntries(w io.Writer, entries []archiveEntry) error {
+	// a zero gzip header has no name or timestamp
+	gz := gzip.NewWriter(w)
+	writer := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:    entry.name,
+			Mode:    int64(normalizedMode(entry)),
+			ModTime: NormalizedModTime,
+		}
+
+		if entry.dir {
+			header.Typeflag = tar.TypeDir
+		} else {
+			header.Typeflag = tar.TypeReg
+			header.Size = int64(len(entry.body))
+		}
+
+		if err := writer.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if _, err := writer.Write(entry.body); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
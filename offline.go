@@ -0,0 +1,151 @@
+package initializr
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Kinds of OfflineArtifact
+const (
+	ArtifactDependency = "dependency"
+	ArtifactBOM        = "bom"
+	ArtifactParent     = "parent"
+	ArtifactPlugin     = "plugin"
+)
+
+// OfflineArtifact is one coordinate a build needs to download. An empty Version means the
+// version is managed by one of the BOMs or the parent in the same manifest
+type OfflineArtifact struct {
+	Kind       string `json:"kind"`
+	GroupID    string `json:"groupId"`
+	ArtifactID string `json:"artifactId"`
+	Version    string `json:"version,omitempty"`
+	Repository string `json:"repository,omitempty"`
+}
+
+// OfflineManifest lists every artifact coordinate and repository a generated project needs,
+// so they can be mirrored before building without internet access
+type OfflineManifest struct {
+	BootVersion  string            `json:"bootVersion"`
+	Artifacts    []OfflineArtifact `json:"artifacts"`
+	Repositories []RepositoryEntry `json:"repositories"`
+}
+
+// BuildOfflineManifest lists what the selected dependencies and the generated build file need
+func BuildOfflineManifest(catalog *DependencyCatalog, dependencyIDs []string, buildFile string, contents []byte) (OfflineManifest, error) {
+	manifest := OfflineManifest{BootVersion: catalog.BootVersion}
+	seen := make(map[string]bool)
+	repos := make(map[string]bool)
+	add := func(a OfflineArtifact) {
+		key := strings.Join([]string{a.Kind, a.GroupID, a.ArtifactID, a.Version}, ":")
+		if !seen[key] {
+			seen[key] = true
+			manifest.Artifacts = append(manifest.Artifacts, a)
+		}
+
+		if a.Repository != "" {
+			repos[a.Repository] = true
+		}
+	}
+
+	// the Boot BOM manages every starter that has no version of its own
+	add(OfflineArtifact{Kind: ArtifactBOM, GroupID: "org.springframework.boot", ArtifactID: "spring-boot-dependencies", Version: catalog.BootVersion})
+
+	for _, id := range dependencyIDs {
+		dep, ok := catalog.Dependencies[id]
+		if !ok {
+			return OfflineManifest{}, fmt.Errorf("dependency %s is not in the catalog for Spring Boot %s", id, catalog.BootVersion)
+		}
+
+		add(OfflineArtifact{Kind: ArtifactDependency, GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: dep.Version, Repository: dep.Repository})
+		if dep.BOM == "" {
+			continue
+		}
+
+		bom, ok := catalog.BOMs[dep.BOM]
+		if !ok {
+			return OfflineManifest{}, fmt.Errorf("dependency %s needs BOM %s, which is not in the catalog", id, dep.BOM)
+		}
+
+		add(OfflineArtifact{Kind: ArtifactBOM, GroupID: bom.GroupID, ArtifactID: bom.ArtifactID, Version: bom.Version})
+		for _, repo := range bom.Repositories {
+			repos[repo] = true
+		}
+	}
+
+	switch name := path.Base(buildFile); name {
+	case "pom.xml":
+		pom, err := ParsePOM(contents)
+		if err != nil {
+			return OfflineManifest{}, err
+		}
+
+		if pom.Parent != nil {
+			add(OfflineArtifact{Kind: ArtifactParent, GroupID: pom.Parent.GroupID, ArtifactID: pom.Parent.ArtifactID, Version: pom.Parent.Version})
+		}
+
+		for _, bom := range pom.BOMImports() {
+			add(OfflineArtifact{Kind: ArtifactBOM, GroupID: bom.GroupID, ArtifactID: bom.ArtifactID, Version: bom.Version})
+		}
+
+		for _, dep := range pom.Dependencies {
+			add(OfflineArtifact{Kind: ArtifactDependency, GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: dep.Version})
+		}
+
+		for _, plugin := range pom.Plugins {
+			groupID := plugin.GroupID
+			if groupID == "" {
+				groupID = "org.apache.maven.plugins"
+			}
+
+			add(OfflineArtifact{Kind: ArtifactPlugin, GroupID: groupID, ArtifactID: plugin.ArtifactID, Version: plugin.Version})
+		}
+	case "build.gradle", "build.gradle.kts":
+		build := ParseGradleBuild(contents)
+		for _, bom := range build.BOMs {
+			add(OfflineArtifact{Kind: ArtifactBOM, GroupID: bom.GroupID, ArtifactID: bom.ArtifactID, Version: bom.Version})
+		}
+
+		for _, dep := range build.Dependencies {
+			// older builds put the Boot plugin on the buildscript classpath
+			kind := ArtifactDependency
+			if dep.Configuration == "classpath" {
+				kind = ArtifactPlugin
+			}
+
+			add(OfflineArtifact{Kind: kind, GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: dep.Version})
+		}
+
+		for _, plugin := range build.Plugins {
+			// core plugins such as java ship with Gradle and have no version
+			if plugin.Version == "" {
+				continue
+			}
+
+			// a plugin ID resolves through its marker artifact, <id>:<id>.gradle.plugin
+			add(OfflineArtifact{Kind: ArtifactPlugin, GroupID: plugin.ID, ArtifactID: plugin.ID + ".gradle.plugin", Version: plugin.Version})
+		}
+	default:
+		return OfflineManifest{}, fmt.Errorf("%s is not a pom.xml or build.gradle(.kts)", buildFile)
+	}
+
+	sort.SliceStable(manifest.Artifacts, func(i, j int) bool {
+		a, b := manifest.Artifacts[i], manifest.Artifacts[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		return a.GroupID+":"+a.ArtifactID < b.GroupID+":"+b.ArtifactID
+	})
+
+	manifest.Repositories = []RepositoryEntry{}
+	for _, repo := range catalog.RepositoryList() {
+		if repos[repo.ID] {
+			manifest.Repositories = append(manifest.Repositories, repo)
+		}
+	}
+
+	return manifest, nil
+}
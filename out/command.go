@@ -0,0 +1,208 @@
+package out
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jghiloni/spring-initializr-resource"
+)
+
+// Command upgrades the Spring Boot version of an existing project
+type Command struct {
+	Client *http.Client
+}
+
+// Run upgrades the project named in the request's params and returns the Boot version it was
+// upgraded to
+func (c *Command) Run(sourcesDir string, request Request) (Response, error) {
+	if strings.TrimSpace(request.Params.Project) == "" {
+		return Response{}, errors.New("the project param is required")
+	}
+
+	client := &initializr.Client{HTTPClient: c.Client, URL: request.Source.URL}
+	bootVersion, err := c.targetBootVersion(client, sourcesDir, request.Params)
+	if err != nil {
+		return Response{}, err
+	}
+
+	version, err := emittedVersion(client, request.Source, bootVersion)
+	if err != nil {
+		return Response{}, err
+	}
+
+	projectDir := filepath.Join(sourcesDir, request.Params.Project)
+	if request.Params.Output != "" {
+		outputDir := filepath.Join(sourcesDir, request.Params.Output)
+
+		// copying a project into itself would never finish
+		if rel, err := filepath.Rel(projectDir, outputDir); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return Response{}, fmt.Errorf("output %s must not be inside project %s", request.Params.Output, request.Params.Project)
+		}
+
+		if err = copyDir(projectDir, outputDir); err != nil {
+			return Response{}, fmt.Errorf("copying %s to %s: %s", request.Params.Project, request.Params.Output, err.Error())
+		}
+
+		projectDir = outputDir
+	}
+
+	buildFile, err := initializr.FindBuildFile(projectDir)
+	if err != nil {
+		return Response{}, err
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(projectDir, buildFile))
+	if err != nil {
+		return Response{}, err
+	}
+
+	metadata := []initializr.MetadataPair{
+		{Name: "build_file", Value: buildFile},
+		{Name: "boot_version", Value: bootVersion.ID},
+	}
+
+	var upgraded []byte
+	if buildFile == "pom.xml" {
+		if upgraded, err = upgradePOM(contents, bootVersion, &metadata); err != nil {
+			return Response{}, err
+		}
+	} else {
+		if upgraded, err = upgradeGradleBuild(client, contents, bootVersion, &metadata); err != nil {
+			return Response{}, err
+		}
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(projectDir, buildFile), upgraded, 0644); err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		Version:  version,
+		Metadata: metadata,
+	}, nil
+}
+
+func (c *Command) targetBootVersion(client *initializr.Client, sourcesDir string, params Params) (initializr.BootVersion, error) {
+	id := strings.TrimSpace(params.BootVersion)
+	if id == "" && params.BootVersionFile != "" {
+		contents, err := ioutil.ReadFile(filepath.Join(sourcesDir, params.BootVersionFile))
+		if err != nil {
+			return initializr.BootVersion{}, fmt.Errorf("reading boot_version_file: %s", err.Error())
+		}
+
+		id = strings.TrimSpace(string(contents))
+	}
+
+	if id == "" {
+		metadata, err := client.Metadata()
+		if err != nil {
+			return initializr.BootVersion{}, err
+		}
+
+		return metadata.DefaultBootVersion()
+	}
+
+	return initializr.ParseBootVersion(id)
+}
+
+// emittedVersion returns the version check emits for bootVersion. Concourse identifies a
+// version by all of its fields, so any other version would be recorded as a new one
+func emittedVersion(client *initializr.Client, source initializr.Source, bootVersion initializr.BootVersion) (initializr.Version, error) {
+	if source.Track == initializr.TrackDependencies {
+		return client.DependencyCatalogVersion(bootVersion)
+	}
+
+	metadata, err := client.Metadata()
+	if err != nil {
+		return initializr.Version{}, err
+	}
+
+	option, ok := metadata.BootVersion.Option(bootVersion.ID)
+	if !ok {
+		return initializr.Version{}, fmt.Errorf("Spring Boot %s is not listed by the Initializr", bootVersion.ID)
+	}
+
+	return initializr.Version{ID: option.ID, Name: option.Name}, nil
+}
+
+func upgradePOM(contents []byte, bootVersion initializr.BootVersion, metadata *[]initializr.MetadataPair) ([]byte, error) {
+	pom, err := initializr.ParsePOM(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	if pom.Parent != nil {
+		*metadata = append(*metadata, initializr.MetadataPair{Name: "previous_boot_version", Value: pom.Parent.Version})
+	}
+
+	return initializr.UpgradePOM(contents, bootVersion.ID)
+}
+
+func upgradeGradleBuild(client *initializr.Client, contents []byte, bootVersion initializr.BootVersion, metadata *[]initializr.MetadataPair) ([]byte, error) {
+	build := initializr.ParseGradleBuild(contents)
+	if plugin, ok := build.Plugin("org.springframework.boot"); ok && plugin.Version != "" {
+		*metadata = append(*metadata, initializr.MetadataPair{Name: "previous_boot_version", Value: plugin.Version})
+	}
+
+	// use the dependency management plugin version a freshly generated build would have
+	var dependencyManagementVersion string
+	if _, ok := build.Plugin(initializr.DependencyManagementPlugin); ok {
+		generated, err := client.Generate("gradle-build", map[string]string{"bootVersion": bootVersion.ID})
+		if err != nil {
+			return nil, fmt.Errorf("generating a build.gradle for Spring Boot %s: %s", bootVersion, err.Error())
+		}
+
+		if plugin, ok := initializr.ParseGradleBuild(generated).Plugin(initializr.DependencyManagementPlugin); ok {
+			dependencyManagementVersion = plugin.Version
+			*metadata = append(*metadata, initializr.MetadataPair{Name: "dependency_management_version", Value: plugin.Version})
+		}
+	}
+
+	return initializr.UpgradeGradleBuild(contents, bootVersion.ID, dependencyManagementVersion)
+}
+
+func copyDir(src, dest string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dest, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		if _, err = io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+
+		return out.Close()
+	})
+}
@@ -0,0 +1,31 @@
+package out
+
+import (
+	"github.com/jghiloni/spring-initializr-resource"
+)
+
+// Params are the put step parameters
+type Params struct {
+	// Project is the path of the project checkout, relative to the sources directory
+	Project string `json:"project"`
+	// BootVersion is the Spring Boot version to upgrade to
+	BootVersion string `json:"boot_version,omitempty"`
+	// BootVersionFile is a file containing the Spring Boot version to upgrade to, such as the
+	// version file written by a get of this resource
+	BootVersionFile string `json:"boot_version_file,omitempty"`
+	// Output is where the upgraded project is written, relative to the sources directory. If
+	// empty, the project is upgraded in place
+	Output string `json:"output,omitempty"`
+}
+
+// Request is the data that is sent to the out script on stdin
+type Request struct {
+	Source initializr.Source `json:"source"`
+	Params Params            `json:"params"`
+}
+
+// Response is the data that the out script writes to stdout
+type Response struct {
+	Version  initializr.Version        `json:"version"`
+	Metadata []initializr.MetadataPair `json:"metadata,omitempty"`
+}
@@ -0,0 +1,211 @@
+package out_test
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	initializr "github.com/jghiloni/spring-initializr-resource"
+	"github.com/jghiloni/spring-initializr-resource/internal"
+	"github.com/jghiloni/spring-initializr-resource/out"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOutCommand(t *testing.T) {
+	spec.Run(t, "Out Command", func(t *testing.T, when spec.G, it spec.S) {
+		when("Testing the out command", func() {
+			var initializrServer *httptest.Server
+			var request out.Request
+			var command *out.Command
+
+			var sourcesDir string
+
+			// copyProject copies a project in testdata/projects into the sources directory
+			copyProject := func(name string) {
+				src := filepath.Join("testdata", "projects", name)
+				err := filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+					Expect(err).NotTo(HaveOccurred())
+
+					rel, err := filepath.Rel(src, p)
+					Expect(err).NotTo(HaveOccurred())
+
+					target := filepath.Join(sourcesDir, name, rel)
+					if info.IsDir() {
+						return os.MkdirAll(target, 0755)
+					}
+
+					contents, err := ioutil.ReadFile(p)
+					Expect(err).NotTo(HaveOccurred())
+
+					return ioutil.WriteFile(target, contents, 0644)
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				request.Params.Project = name
+			}
+
+			// readFile returns a file in the sources directory as a string
+			readFile := func(p string) string {
+				contents, err := ioutil.ReadFile(filepath.Join(sourcesDir, p))
+				Expect(err).NotTo(HaveOccurred())
+
+				return string(contents)
+			}
+
+			it.Before(func() {
+				RegisterTestingT(t)
+
+				dataDir, err := filepath.Abs("testdata")
+				Expect(err).NotTo(HaveOccurred())
+
+				initializrServer = internal.MockInitializrServer(dataDir)
+
+				serverURL, err := url.Parse(initializrServer.URL)
+				Expect(err).NotTo(HaveOccurred())
+
+				request = out.Request{
+					Source: initializr.Source{
+						URL:               serverURL,
+						SkipTLSValidation: true,
+					},
+					Params: out.Params{
+						BootVersion: "2.1.0.RELEASE",
+					},
+				}
+
+				client, err := initializr.NewHTTPClient(request.Source)
+				Expect(err).NotTo(HaveOccurred())
+
+				command = &out.Command{
+					Client: client,
+				}
+
+				sourcesDir, err = ioutil.TempDir("", "out_command")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it.After(func() {
+				initializrServer.Close()
+				os.RemoveAll(sourcesDir)
+			})
+
+			it("Should require a project", func() {
+				_, err := command.Run(sourcesDir, request)
+				Expect(err).To(MatchError("the project param is required"))
+			})
+
+			it("Should upgrade the parent of a POM in place", func() {
+				copyProject("maven-service")
+
+				resp, err := command.Run(sourcesDir, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Version).To(Equal(initializr.Version{ID: "2.1.0.RELEASE", Name: "2.1.0"}))
+				Expect(resp.Metadata).To(Equal([]initializr.MetadataPair{
+					{Name: "build_file", Value: "pom.xml"},
+					{Name: "boot_version", Value: "2.1.0.RELEASE"},
+					{Name: "previous_boot_version", Value: "2.0.1.RELEASE"},
+				}))
+
+				pom := readFile("maven-service/pom.xml")
+				Expect(pom).To(ContainSubstring("<artifactId>spring-boot-starter-parent</artifactId>\n\t\t<version>2.1.0.RELEASE</version>"))
+				Expect(pom).To(ContainSubstring("<version>1.4.0-SNAPSHOT</version>"))
+				Expect(pom).To(ContainSubstring("<version>25.1-jre</version>"))
+				Expect(pom).NotTo(ContainSubstring("2.0.1.RELEASE"))
+			})
+
+			it("Should upgrade the Boot plugin of a Gradle build and align dependency management", func() {
+				copyProject("gradle-service")
+
+				resp, err := command.Run(sourcesDir, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Metadata).To(Equal([]initializr.MetadataPair{
+					{Name: "build_file", Value: "build.gradle"},
+					{Name: "boot_version", Value: "2.1.0.RELEASE"},
+					{Name: "previous_boot_version", Value: "2.0.2.RELEASE"},
+					{Name: "dependency_management_version", Value: "1.0.6.RELEASE"},
+				}))
+
+				build := readFile("gradle-service/build.gradle")
+				Expect(build).To(ContainSubstring("id 'org.springframework.boot' version '2.1.0.RELEASE'"))
+				Expect(build).To(ContainSubstring("id 'io.spring.dependency-management' version '1.0.6.RELEASE'"))
+				Expect(build).To(ContainSubstring("implementation 'org.apache.commons:commons-lang3:3.7'"))
+			})
+
+			it("Should upgrade a Kotlin DSL build", func() {
+				copyProject("kotlin-service")
+
+				resp, err := command.Run(sourcesDir, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Metadata[0]).To(Equal(initializr.MetadataPair{Name: "build_file", Value: "build.gradle.kts"}))
+
+				build := readFile("kotlin-service/build.gradle.kts")
+				Expect(build).To(ContainSubstring(`id("org.springframework.boot") version "2.1.0.RELEASE"`))
+				Expect(build).To(ContainSubstring(`id("io.spring.dependency-management") version "1.0.6.RELEASE"`))
+				Expect(build).To(ContainSubstring(`kotlin("jvm") version "1.2.41"`))
+			})
+
+			it("Should upgrade the springBootVersion of an older Gradle build to the default version", func() {
+				copyProject("legacy-gradle-service")
+				request.Params.BootVersion = ""
+
+				resp, err := command.Run(sourcesDir, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Version).To(Equal(initializr.Version{ID: "2.0.2.RELEASE", Name: "2.0.2"}))
+				Expect(resp.Metadata).To(ContainElement(initializr.MetadataPair{Name: "previous_boot_version", Value: "1.5.13.RELEASE"}))
+
+				Expect(readFile("legacy-gradle-service/build.gradle")).To(ContainSubstring("springBootVersion = '2.0.2.RELEASE'"))
+			})
+
+			it("Should read the version from boot_version_file", func() {
+				copyProject("maven-service")
+				request.Params.BootVersion = ""
+				request.Params.BootVersionFile = "version"
+
+				err := ioutil.WriteFile(filepath.Join(sourcesDir, "version"), []byte("2.0.3.BUILD-SNAPSHOT\n"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				resp, err := command.Run(sourcesDir, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Version).To(Equal(initializr.Version{ID: "2.0.3.BUILD-SNAPSHOT", Name: "2.0.3 (SNAPSHOT)"}))
+			})
+
+			when("Writing the upgraded project to output", func() {
+				it("Should copy the project and leave the original alone", func() {
+					copyProject("maven-service")
+					request.Params.Output = "upgraded-service"
+
+					_, err := command.Run(sourcesDir, request)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(readFile("upgraded-service/pom.xml")).To(ContainSubstring("<version>2.1.0.RELEASE</version>"))
+					Expect(readFile("upgraded-service/src/main/resources/application.properties")).To(Equal("server.port=8081\n"))
+					Expect(readFile("maven-service/pom.xml")).To(ContainSubstring("<version>2.0.1.RELEASE</version>"))
+				})
+
+				it("Should reject an output inside the project", func() {
+					copyProject("maven-service")
+					request.Params.Output = "maven-service/upgraded"
+
+					_, err := command.Run(sourcesDir, request)
+					Expect(err).To(MatchError("output maven-service/upgraded must not be inside project maven-service"))
+					Expect(filepath.Join(sourcesDir, "maven-service", "upgraded")).NotTo(BeAnExistingFile())
+				})
+
+				it("Should reject the project itself as output", func() {
+					copyProject("maven-service")
+					request.Params.Output = "./maven-service"
+
+					_, err := command.Run(sourcesDir, request)
+					Expect(err).To(MatchError("output ./maven-service must not be inside project maven-service"))
+				})
+			})
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,13 @@
+plugins {
+	id("org.springframework.boot") version "2.0.2.RELEASE"
+	id("io.spring.dependency-management") version "1.0.5.RELEASE"
+	kotlin("jvm") version "1.2.41"
+}
+
+group = "com.example"
+version = "1.4.0-SNAPSHOT"
+
+dependencies {
+	implementation("org.springframework.boot:spring-boot-starter-web")
+	implementation("org.jetbrains.kotlin:kotlin-stdlib-jdk8")
+}
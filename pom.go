@@ -0,0 +1,151 @@
+package initializr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MavenDependency is a dependency of a POM, either direct or under dependencyManagement
+type MavenDependency struct {
+	GroupID    string `xml:"groupId" json:"groupId"`
+	ArtifactID string `xml:"artifactId" json:"artifactId"`
+	Version    string `xml:"version" json:"version,omitempty"`
+	Type       string `xml:"type" json:"type,omitempty"`
+	Scope      string `xml:"scope" json:"scope,omitempty"`
+}
+
+// MavenPlugin is a build plugin declared in a POM
+type MavenPlugin struct {
+	GroupID    string `xml:"groupId" json:"groupId"`
+	ArtifactID string `xml:"artifactId" json:"artifactId"`
+	Version    string `xml:"version" json:"version,omitempty"`
+}
+
+// MavenParent is the parent of a POM
+type MavenParent struct {
+	GroupID    string `xml:"groupId" json:"groupId"`
+	ArtifactID string `xml:"artifactId" json:"artifactId"`
+	Version    string `xml:"version" json:"version"`
+}
+
+// MavenRepository is a repository or plugin repository declared in a POM
+type MavenRepository struct {
+	ID   string `xml:"id" json:"id"`
+	Name string `xml:"name" json:"name,omitempty"`
+	URL  string `xml:"url" json:"url"`
+}
+
+// MavenProperties are the properties of a POM, keyed by element name
+type MavenProperties map[string]string
+
+// UnmarshalXML reads each child element of <properties> as a property
+func (m *MavenProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*m = make(MavenProperties)
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err = d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+
+			(*m)[t.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// POM is the part of a pom.xml that the Initializr generates
+type POM struct {
+	XMLName              xml.Name          `xml:"project" json:"-"`
+	Parent               *MavenParent      `xml:"parent" json:"parent,omitempty"`
+	GroupID              string            `xml:"groupId" json:"groupId"`
+	ArtifactID           string            `xml:"artifactId" json:"artifactId"`
+	Version              string            `xml:"version" json:"version"`
+	Packaging            string            `xml:"packaging" json:"packaging,omitempty"`
+	Name                 string            `xml:"name" json:"name,omitempty"`
+	Description          string            `xml:"description" json:"description,omitempty"`
+	Properties           MavenProperties   `xml:"properties" json:"properties,omitempty"`
+	Dependencies         []MavenDependency `xml:"dependencies>dependency" json:"dependencies"`
+	DependencyManagement []MavenDependency `xml:"dependencyManagement>dependencies>dependency" json:"dependencyManagement"`
+	Plugins              []MavenPlugin     `xml:"build>plugins>plugin" json:"plugins"`
+	Repositories         []MavenRepository `xml:"repositories>repository" json:"repositories,omitempty"`
+	PluginRepositories   []MavenRepository `xml:"pluginRepositories>pluginRepository" json:"pluginRepositories,omitempty"`
+}
+
+var pomPropertyReference = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// ParsePOM parses a pom.xml. Property references such as ${spring-cloud.version} in
+// dependency and plugin versions are resolved against the POM's own properties
+func ParsePOM(contents []byte) (*POM, error) {
+	var pom POM
+	if err := xml.Unmarshal(contents, &pom); err != nil {
+		return nil, fmt.Errorf("parsing pom.xml: %s", err.Error())
+	}
+
+	for _, deps := range [][]MavenDependency{pom.Dependencies, pom.DependencyManagement} {
+		for i := range deps {
+			deps[i].Version = pom.resolve(deps[i].Version)
+		}
+	}
+
+	for i := range pom.Plugins {
+		pom.Plugins[i].Version = pom.resolve(pom.Plugins[i].Version)
+	}
+
+	return &pom, nil
+}
+
+func (p *POM) resolve(s string) string {
+	return pomPropertyReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		switch name {
+		case "project.version":
+			return p.Version
+		case "project.parent.version":
+			if p.Parent != nil {
+				return p.Parent.Version
+			}
+		}
+
+		if val, ok := p.Properties[name]; ok {
+			return val
+		}
+
+		return ref
+	})
+}
+
+// BOMImports returns the dependencyManagement entries that import a BOM
+func (p *POM) BOMImports() []MavenDependency {
+	var boms []MavenDependency
+	for _, dep := range p.DependencyManagement {
+		if dep.Scope == "import" && dep.Type == "pom" {
+			boms = append(boms, dep)
+		}
+	}
+
+	return boms
+}
+
+// MetadataPairs returns the values of the POM most worth showing in Concourse
+func (p *POM) MetadataPairs() []MetadataPair {
+	var pairs []MetadataPair
+	if p.Parent != nil {
+		pairs = append(pairs, MetadataPair{Name: "parent_version", Value: p.Parent.Version})
+	}
+
+	if javaVersion, ok := p.Properties["java.version"]; ok {
+		pairs = append(pairs, MetadataPair{Name: "java_version", Value: javaVersion})
+	}
+
+	return pairs
+}
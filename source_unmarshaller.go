@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"regexp"
 	"strconv"
+
+	"github.com/blang/semver"
 )
 
 // UnmarshalJSON unmarshals and verifies the source json block
@@ -25,6 +27,14 @@ func (s *Source) UnmarshalJSON(j []byte) error {
 		intermediate["url"] = "https://start.spring.io"
 	}
 
+	if _, ok := intermediate["track"]; !ok {
+		intermediate["track"] = TrackLatest
+	}
+
+	if _, ok := intermediate["on_delisted"]; !ok {
+		intermediate["on_delisted"] = DelistedNewer
+	}
+
 	for key, val := range intermediate {
 		switch key {
 		case "url":
@@ -43,10 +53,51 @@ func (s *Source) UnmarshalJSON(j []byte) error {
 			} else {
 				return fmt.Errorf("product_version needs to be a string, got a %T", val)
 			}
+		case "version_constraint":
+			if constraint, ok := val.(string); ok {
+				if s.VersionConstraint, err = semver.ParseRange(constraint); err != nil {
+					return fmt.Errorf("version_constraint %q is not a valid semver range: %s", constraint, err.Error())
+				}
+			} else {
+				return fmt.Errorf("version_constraint needs to be a string, got a %T", val)
+			}
 		case "include_snapshots":
 			if s.IncludeSnapshots, err = makeBool(val); err != nil {
 				return err
 			}
+		case "release_channels":
+			var channels []string
+			if channels, err = makeStringSlice(val); err != nil {
+				return fmt.Errorf("release_channels: %s", err.Error())
+			}
+
+			s.ReleaseChannels = make([]ReleaseType, 0, len(channels))
+			for _, channel := range channels {
+				var r ReleaseType
+				if r, err = ParseReleaseType(channel); err != nil {
+					return err
+				}
+
+				s.ReleaseChannels = append(s.ReleaseChannels, r)
+			}
+		case "track":
+			switch val {
+			case TrackLatest, TrackDefault, TrackDependencies:
+				s.Track = val.(string)
+			default:
+				return fmt.Errorf("track must be one of %s, %s or %s, got %v", TrackLatest, TrackDefault, TrackDependencies, val)
+			}
+		case "on_delisted":
+			switch val {
+			case DelistedNewer, DelistedLatest, DelistedError:
+				s.OnDelisted = val.(string)
+			default:
+				return fmt.Errorf("on_delisted must be one of %s, %s or %s, got %v", DelistedNewer, DelistedLatest, DelistedError, val)
+			}
+		case "required_dependencies":
+			if s.RequiredDependencies, err = makeStringSlice(val); err != nil {
+				return fmt.Errorf("required_dependencies: %s", err.Error())
+			}
 		case "ca_certs":
 			if _, ok := val.([]string); ok {
 				for _, certPEM := range val.([]string) {
@@ -120,6 +171,25 @@ func makeBool(val interface{}) (bool, error) {
 	return false, fmt.Errorf("Expected bool or boolean string, got a %T instead", val)
 }
 
+func makeStringSlice(val interface{}) ([]string, error) {
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Expected a list of strings, got a %T instead", val)
+	}
+
+	strs := make([]string, 0, len(list))
+	for _, item := range list {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("Expected a list of strings, got a %T in the list", item)
+		}
+
+		strs = append(strs, str)
+	}
+
+	return strs, nil
+}
+
 func makeCertificate(certPEM string) (*x509.Certificate, error) {
 	block, _ := pem.Decode([]byte(certPEM))
 	if block == nil {
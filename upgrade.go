@@ -0,0 +1,59 @@
+package initializr
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DependencyManagementPlugin is the ID of the Gradle plugin that imports the Boot BOM
+const DependencyManagementPlugin = "io.spring.dependency-management"
+
+var (
+	pomParentPattern        = regexp.MustCompile(`(?s)<parent>.*?</parent>`)
+	pomBootParentPattern    = regexp.MustCompile(`<artifactId>\s*spring-boot-starter-parent\s*</artifactId>`)
+	pomVersionPattern       = regexp.MustCompile(`(<version>)\s*[^<]*?\s*(</version>)`)
+	gradleBootPluginPattern = regexp.MustCompile(`(id[ \t]*\(?[ \t]*["']org\.springframework\.boot["'][ \t]*\)?[ \t]+version[ \t]*\(?[ \t]*["'])[^"']+(["'])`)
+	gradleBootVarPattern    = regexp.MustCompile(`(springBootVersion[ \t]*=[ \t]*["'])[^"']+(["'])`)
+	gradleDepMgmtPattern    = regexp.MustCompile(`(id[ \t]*\(?[ \t]*["']io\.spring\.dependency-management["'][ \t]*\)?[ \t]+version[ \t]*\(?[ \t]*["'])[^"']+(["'])`)
+)
+
+// UpgradePOM sets the version of the spring-boot-starter-parent in a pom.xml, leaving the
+// rest of the file untouched
+func UpgradePOM(contents []byte, bootVersion string) ([]byte, error) {
+	loc := pomParentPattern.FindIndex(contents)
+	if loc == nil || !pomBootParentPattern.Match(contents[loc[0]:loc[1]]) {
+		return nil, fmt.Errorf("pom.xml does not have spring-boot-starter-parent as its parent")
+	}
+
+	parent := contents[loc[0]:loc[1]]
+	if !pomVersionPattern.Match(parent) {
+		return nil, fmt.Errorf("the spring-boot-starter-parent in pom.xml has no version")
+	}
+
+	upgraded := make([]byte, 0, len(contents)+len(bootVersion))
+	upgraded = append(upgraded, contents[:loc[0]]...)
+	upgraded = append(upgraded, pomVersionPattern.ReplaceAll(parent, []byte("${1}"+bootVersion+"${2}"))...)
+	return append(upgraded, contents[loc[1]:]...), nil
+}
+
+// UpgradeGradleBuild sets the version of the org.springframework.boot plugin, or the
+// springBootVersion property older builds use, in a build.gradle or build.gradle.kts. If
+// dependencyManagementVersion is not empty, the io.spring.dependency-management plugin is
+// set to it as well. The rest of the file is untouched
+func UpgradeGradleBuild(contents []byte, bootVersion, dependencyManagementVersion string) ([]byte, error) {
+	var upgraded []byte
+	switch {
+	case gradleBootPluginPattern.Match(contents):
+		upgraded = gradleBootPluginPattern.ReplaceAll(contents, []byte("${1}"+bootVersion+"${2}"))
+	case gradleBootVarPattern.Match(contents):
+		upgraded = gradleBootVarPattern.ReplaceAll(contents, []byte("${1}"+bootVersion+"${2}"))
+	default:
+		return nil, fmt.Errorf("the build does not declare a version for the org.springframework.boot plugin")
+	}
+
+	if dependencyManagementVersion != "" {
+		upgraded = gradleDepMgmtPattern.ReplaceAll(upgraded, []byte("${1}"+dependencyManagementVersion+"${2}"))
+	}
+
+	return upgraded, nil
+}
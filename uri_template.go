@@ -0,0 +1,124 @@
+package initializr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type templateOperator struct {
+	first        string
+	sep          string
+	named        bool
+	ifEmpty      string
+	allowReserve bool
+}
+
+var templateOperators = map[byte]templateOperator{
+	'+': {first: "", sep: ",", allowReserve: true},
+	'#': {first: "#", sep: ",", allowReserve: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// ExpandURITemplate expands an RFC 6570 URI template, such as the templated hrefs under _links,
+// with string values. Variables without a value are left out, as the RFC requires
+func ExpandURITemplate(template string, values map[string]string) (string, error) {
+	var expanded strings.Builder
+
+	for len(template) > 0 {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			expanded.WriteString(template)
+			break
+		}
+
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated expression in URI template %q", template)
+		}
+
+		expanded.WriteString(template[:start])
+		expression, err := expandExpression(template[start+1:start+end], values)
+		if err != nil {
+			return "", err
+		}
+
+		expanded.WriteString(expression)
+		template = template[start+end+1:]
+	}
+
+	return expanded.String(), nil
+}
+
+func expandExpression(expression string, values map[string]string) (string, error) {
+	if expression == "" {
+		return "", fmt.Errorf("empty expression in URI template")
+	}
+
+	op, ok := templateOperators[expression[0]]
+	if ok {
+		expression = expression[1:]
+	} else {
+		op = templateOperator{first: "", sep: ","}
+	}
+
+	var parts []string
+	for _, spec := range strings.Split(expression, ",") {
+		name, prefix := strings.TrimSuffix(spec, "*"), -1
+		if i := strings.IndexByte(name, ':'); i >= 0 {
+			n, err := strconv.Atoi(name[i+1:])
+			if err != nil || n <= 0 {
+				return "", fmt.Errorf("invalid prefix modifier in URI template variable %q", spec)
+			}
+
+			name, prefix = name[:i], n
+		}
+
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+
+		if prefix >= 0 && prefix < len([]rune(value)) {
+			value = string([]rune(value)[:prefix])
+		}
+
+		encoded := encodeTemplateValue(value, op.allowReserve)
+		switch {
+		case !op.named:
+			parts = append(parts, encoded)
+		case value == "":
+			parts = append(parts, name+op.ifEmpty)
+		default:
+			parts = append(parts, name+"="+encoded)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	return op.first + strings.Join(parts, op.sep), nil
+}
+
+const unreservedChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-._~"
+const reservedChars = ":/?#[]@!$&'()*+,;="
+
+func encodeTemplateValue(value string, allowReserved bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if strings.IndexByte(unreservedChars, c) >= 0 || (allowReserved && strings.IndexByte(reservedChars, c) >= 0) {
+			encoded.WriteByte(c)
+			continue
+		}
+
+		fmt.Fprintf(&encoded, "%%%02X", c)
+	}
+
+	return encoded.String()
+}
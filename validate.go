@@ -0,0 +1,185 @@
+package initializr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InvalidParam describes a single project parameter that the Initializr would reject
+type InvalidParam struct {
+	Name       string
+	Value      string
+	Reason     string
+	Allowed    []string
+	Suggestion string
+}
+
+func (p InvalidParam) String() string {
+	msg := fmt.Sprintf("%s: %q %s", p.Name, p.Value, p.Reason)
+	if p.Suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", p.Suggestion)
+	}
+
+	if len(p.Allowed) > 0 {
+		msg += fmt.Sprintf("; allowed values are %s", strings.Join(p.Allowed, ", "))
+	}
+
+	return msg
+}
+
+// ValidationError lists every invalid project parameter found by ValidateParams
+type ValidationError []InvalidParam
+
+func (v ValidationError) Error() string {
+	lines := make([]string, 0, len(v)+1)
+	lines = append(lines, "invalid project parameters:")
+	for _, p := range v {
+		lines = append(lines, "  "+p.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ValidateParams checks project parameters against the values the Initializr allows before
+// a project is requested. params are keyed by Initializr parameter name (type, packaging,
+// language, javaVersion, bootVersion, dependencies); empty or missing values use the
+// Initializr's defaults and are not checked. Dependencies are also checked against their
+// versionRange for the requested (or default) Boot version. If anything is invalid, the
+// returned error is a ValidationError naming every bad value
+func (m *Metadata) ValidateParams(params map[string]string) error {
+	var invalid ValidationError
+
+	fields := []struct {
+		name  string
+		field SelectField
+	}{
+		{"type", m.Type},
+		{"packaging", m.Packaging},
+		{"language", m.Language},
+		{"javaVersion", m.JavaVersion},
+		{"bootVersion", m.BootVersion},
+	}
+
+	for _, f := range fields {
+		value := strings.TrimSpace(params[f.name])
+		if value == "" {
+			continue
+		}
+
+		if _, ok := f.field.Option(value); !ok {
+			allowed := f.field.IDs()
+			invalid = append(invalid, InvalidParam{
+				Name:       f.name,
+				Value:      value,
+				Reason:     "is not supported",
+				Allowed:    allowed,
+				Suggestion: closestMatch(value, allowed),
+			})
+		}
+	}
+
+	bootVersionID := strings.TrimSpace(params["bootVersion"])
+	if bootVersionID == "" {
+		bootVersionID = m.BootVersion.Default
+	}
+
+	bootVersion, bootVersionErr := ParseBootVersion(bootVersionID)
+
+	allDependencies := m.DependencyIDs()
+	for _, id := range strings.Split(params["dependencies"], ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+
+		dep, _, ok := m.Dependency(id)
+		if !ok {
+			// the full list is far too long to be useful in an error message
+			invalid = append(invalid, InvalidParam{
+				Name:       "dependencies",
+				Value:      id,
+				Reason:     "is not a known dependency",
+				Suggestion: closestMatch(id, allDependencies),
+			})
+			continue
+		}
+
+		if bootVersionErr != nil {
+			continue
+		}
+
+		available, err := dep.Available(bootVersion)
+		if err != nil {
+			return err
+		}
+
+		if !available {
+			invalid = append(invalid, InvalidParam{
+				Name:   "dependencies",
+				Value:  id,
+				Reason: fmt.Sprintf("requires Spring Boot %s, not %s", dep.VersionRange, bootVersion),
+			})
+		}
+	}
+
+	if len(invalid) > 0 {
+		return invalid
+	}
+
+	return nil
+}
+
+// closestMatch returns the candidate closest to value by edit distance, if it is close enough
+// to be a plausible typo
+func closestMatch(value string, candidates []string) string {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		d := editDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	threshold := len(value) / 3
+	if threshold < 2 {
+		threshold = 2
+	}
+
+	if bestDistance < 0 || bestDistance > threshold {
+		return ""
+	}
+
+	return best
+}
+
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
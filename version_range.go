@@ -0,0 +1,112 @@
+package initializr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VersionRange is a Maven-style version range as used by the versionRange fields of the
+// Initializr metadata, e.g. [2.0.0.RELEASE,2.1.0.M1) or (2.7.0,3.2.0-M1]. A bare version
+// such as 1.3.0.RELEASE means that version or newer. A nil bound is unbounded
+type VersionRange struct {
+	Lower          *BootVersion
+	LowerInclusive bool
+	Upper          *BootVersion
+	UpperInclusive bool
+}
+
+// ParseVersionRange parses a versionRange expression. An empty expression matches every version
+func ParseVersionRange(expr string) (VersionRange, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return VersionRange{}, nil
+	}
+
+	first, last := expr[0], expr[len(expr)-1]
+	if first != '[' && first != '(' {
+		lower, err := ParseBootVersion(expr)
+		if err != nil {
+			return VersionRange{}, fmt.Errorf("invalid version range %q: %s", expr, err.Error())
+		}
+
+		return VersionRange{Lower: &lower, LowerInclusive: true}, nil
+	}
+
+	if last != ']' && last != ')' {
+		return VersionRange{}, fmt.Errorf("invalid version range %q: must end with ] or )", expr)
+	}
+
+	bounds := strings.Split(expr[1:len(expr)-1], ",")
+	if len(bounds) != 2 {
+		return VersionRange{}, fmt.Errorf("invalid version range %q: expected a lower and upper bound", expr)
+	}
+
+	r := VersionRange{LowerInclusive: first == '[', UpperInclusive: last == ']'}
+	for i, bound := range []**BootVersion{&r.Lower, &r.Upper} {
+		id := strings.TrimSpace(bounds[i])
+		if id == "" {
+			continue
+		}
+
+		v, err := ParseBootVersion(id)
+		if err != nil {
+			return VersionRange{}, fmt.Errorf("invalid version range %q: %s", expr, err.Error())
+		}
+
+		*bound = &v
+	}
+
+	if r.Lower != nil && r.Upper != nil && r.Upper.LessThan(*r.Lower) {
+		return VersionRange{}, fmt.Errorf("invalid version range %q: upper bound is lower than lower bound", expr)
+	}
+
+	return r, nil
+}
+
+// Contains reports whether v falls within the range
+func (r VersionRange) Contains(v BootVersion) bool {
+	if r.Lower != nil {
+		cmp := v.Compare(*r.Lower)
+		if cmp < 0 || (cmp == 0 && !r.LowerInclusive) {
+			return false
+		}
+	}
+
+	if r.Upper != nil {
+		cmp := v.Compare(*r.Upper)
+		if cmp > 0 || (cmp == 0 && !r.UpperInclusive) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r VersionRange) String() string {
+	if r.Lower == nil && r.Upper == nil {
+		return ""
+	}
+
+	if r.Upper == nil && r.LowerInclusive {
+		return r.Lower.ID
+	}
+
+	lower, upper := "(", ")"
+	if r.LowerInclusive {
+		lower = "["
+	}
+
+	if r.UpperInclusive {
+		upper = "]"
+	}
+
+	if r.Lower != nil {
+		lower += r.Lower.ID
+	}
+
+	if r.Upper != nil {
+		upper = r.Upper.ID + upper
+	}
+
+	return lower + "," + upper
+}
@@ -0,0 +1,141 @@
+package initializr_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+
+	"github.com/jghiloni/spring-initializr-resource"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestVersionRange(t *testing.T) {
+	spec.Run(t, "Version Range", func(t *testing.T, when spec.G, it spec.S) {
+		it.Before(func() {
+			RegisterTestingT(t)
+		})
+
+		// contains parses a Boot version and reports whether r contains it
+		contains := func(r initializr.VersionRange, id string) bool {
+			v, err := initializr.ParseBootVersion(id)
+			Expect(err).NotTo(HaveOccurred())
+
+			return r.Contains(v)
+		}
+
+		when("Parsing a valid range", func() {
+			for _, c := range []struct {
+				name     string
+				expr     string
+				included []string
+				excluded []string
+			}{
+				{
+					name:     "an empty expression matches every version",
+					expr:     "",
+					included: []string{"1.5.13.RELEASE", "3.2.0-SNAPSHOT"},
+				},
+				{
+					name:     "a bare version is a minimum",
+					expr:     "1.3.0.RELEASE",
+					included: []string{"1.3.0.RELEASE", "3.1.5"},
+					excluded: []string{"1.2.8.RELEASE"},
+				},
+				{
+					name:     "an inclusive lower and exclusive upper bound",
+					expr:     "[2.0.0.RELEASE,2.1.0.M1)",
+					included: []string{"2.0.0.RELEASE", "2.0.9.RELEASE"},
+					excluded: []string{"1.5.13.RELEASE", "2.1.0.M1", "2.1.0.RELEASE"},
+				},
+				{
+					name:     "an exclusive lower and inclusive upper bound",
+					expr:     "(2.0.0.RELEASE,2.1.0.RELEASE]",
+					included: []string{"2.0.1.RELEASE", "2.1.0.RELEASE"},
+					excluded: []string{"2.0.0.RELEASE", "2.1.1.RELEASE"},
+				},
+				{
+					name:     "an open upper bound",
+					expr:     "[2.7.0,)",
+					included: []string{"2.7.0", "3.2.0"},
+					excluded: []string{"2.6.15"},
+				},
+				{
+					name:     "an open lower bound",
+					expr:     "(,2.7.0]",
+					included: []string{"1.5.13.RELEASE", "2.7.0"},
+					excluded: []string{"2.7.1"},
+				},
+				{
+					name:     "modern qualifiers in both bounds",
+					expr:     "[3.0.0-M1,3.2.0-RC1)",
+					included: []string{"3.0.0-M1", "3.1.5", "3.2.0-M3"},
+					excluded: []string{"2.7.18", "3.2.0-RC1", "3.2.0"},
+				},
+				{
+					name:     "a legacy lower and a modern upper bound",
+					expr:     "[2.7.0.RELEASE,3.0.0-M1)",
+					included: []string{"2.7.0", "2.7.18"},
+					excluded: []string{"2.6.15", "3.0.0-M1", "3.0.0"},
+				},
+			} {
+				c := c
+
+				it("Should accept "+c.name, func() {
+					r, err := initializr.ParseVersionRange(c.expr)
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, id := range c.included {
+						Expect(contains(r, id)).To(BeTrue(), "%s should contain %s", c.expr, id)
+					}
+
+					for _, id := range c.excluded {
+						Expect(contains(r, id)).To(BeFalse(), "%s should not contain %s", c.expr, id)
+					}
+				})
+			}
+		})
+
+		when("Parsing an invalid range", func() {
+			for _, c := range []struct {
+				name string
+				expr string
+				err  string
+			}{
+				{
+					name: "a missing closing bracket",
+					expr: "[2.0.0.RELEASE,2.1.0.RELEASE",
+					err:  `invalid version range "[2.0.0.RELEASE,2.1.0.RELEASE": must end with ] or )`,
+				},
+				{
+					name: "a missing opening bracket",
+					expr: "2.0.0.RELEASE,2.1.0.RELEASE)",
+					err:  `invalid version range "2.0.0.RELEASE,2.1.0.RELEASE)": "2.0.0.RELEASE,2.1.0.RELEASE)" is not a recognized Spring Boot version`,
+				},
+				{
+					name: "only one bound",
+					expr: "[2.0.0.RELEASE]",
+					err:  `invalid version range "[2.0.0.RELEASE]": expected a lower and upper bound`,
+				},
+				{
+					name: "reversed bounds",
+					expr: "[2.1.0.RELEASE,2.0.0.RELEASE)",
+					err:  `invalid version range "[2.1.0.RELEASE,2.0.0.RELEASE)": upper bound is lower than lower bound`,
+				},
+				{
+					name: "a bound that is not a version",
+					expr: "[2.0.0.RELEASE,2.x)",
+					err:  `invalid version range "[2.0.0.RELEASE,2.x)": "2.x" is not a recognized Spring Boot version`,
+				},
+			} {
+				c := c
+
+				it("Should reject "+c.name, func() {
+					_, err := initializr.ParseVersionRange(c.expr)
+					Expect(err).To(MatchError(c.err))
+				})
+			}
+		})
+	}, spec.Report(report.Terminal{}))
+}
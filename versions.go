@@ -0,0 +1,84 @@
+package initializr
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// CheckVersions returns the versions check should emit under the Concourse check contract.
+// listed is the bootVersion field as the Initializr serves it, before versions were left out
+// of m, and decides whether the current version has been delisted
+func (m *Metadata) CheckVersions(source Source, current *Version, listed SelectField) ([]Version, error) {
+	versions, err := m.BootVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(m.BootVersion.Values))
+	for _, option := range m.BootVersion.Values {
+		names[option.ID] = option.Name
+	}
+
+	var included BootVersions
+	for _, v := range versions {
+		if source.IncludesVersion(v) {
+			included = append(included, v)
+		}
+	}
+
+	sort.Sort(included)
+	if len(included) == 0 {
+		return []Version{}, nil
+	}
+
+	emit := func(versions BootVersions) []Version {
+		out := make([]Version, 0, len(versions))
+		for _, v := range versions {
+			out = append(out, Version{ID: v.ID, Name: names[v.ID]})
+		}
+
+		return out
+	}
+
+	latest := included[len(included)-1:]
+	if current == nil || current.ID == "" {
+		return emit(latest), nil
+	}
+
+	for i, v := range included {
+		if v.ID == current.ID {
+			return emit(included[i:]), nil
+		}
+	}
+
+	// a version the Initializr still lists but the source leaves out is superseded by the
+	// newer versions the source includes, without applying the on_delisted policy
+	_, stillListed := listed.Option(current.ID)
+
+	if !stillListed {
+		switch source.OnDelisted {
+		case DelistedError:
+			return nil, fmt.Errorf("current version %s is no longer listed by the Initializr", current.ID)
+		case DelistedLatest:
+			log.Printf("current version %s is no longer listed by the Initializr, emitting the latest version %s", current.ID, latest[0].ID)
+			return emit(latest), nil
+		}
+	}
+
+	currentVersion, err := ParseBootVersion(current.ID)
+	if err != nil {
+		log.Printf("current version %s is no longer listed by the Initializr and cannot be compared (%s), emitting the latest version %s", current.ID, err.Error(), latest[0].ID)
+		return emit(latest), nil
+	}
+
+	start := sort.Search(len(included), func(i int) bool {
+		return currentVersion.LessThan(included[i])
+	})
+
+	if !stillListed {
+		log.Printf("current version %s is no longer listed by the Initializr, emitting the %d listed versions newer than it", current.ID, len(included)-start)
+	}
+
+	return emit(included[start:]), nil
+}